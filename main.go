@@ -1,12 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"awp/pkg/cli"
+	"awp/pkg/commands"
 	"awp/pkg/config"
 	"awp/pkg/database"
 	"awp/pkg/ui"
@@ -14,24 +16,53 @@ import (
 )
 
 func main() {
-	utils.Log("=== Starting AWP 0.2 ===")
-
 	// Parse command line arguments
 	args := cli.ParseArgs()
 
-	// Initialize logger
+	// Initialize logger, holding messages until the config says where the log goes
 	utils.InitLogger(args.Verbose)
 	defer utils.CloseLogger()
+	utils.Log("=== Starting AWP 0.2 ===")
 
 	// Load configuration and styles
-	cfg, styles, err := config.Load(args.ConfigPath)
+	cfg, styles, err := config.Load(args.ConfigPath, args.StrictConfig)
+	utils.ConfigureLogger(cfg.LogDir, cfg.LogRetentionDays)
+
+	stylesWarning := ""
+	if errors.Is(err, config.ErrInvalidStyles) {
+		// Carry on with the default colors
+		utils.Log("Error loading styles: %v", err)
+		stylesWarning = fmt.Sprintf("%v - using default colors", err)
+		err = nil
+	}
 	if err != nil {
 		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Decide which calendar day it is in the configured time zone
+	if err := utils.SetTimezone(cfg.Timezone); err != nil {
+		fmt.Printf("Error loading timezone: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The command line can force read-only mode on top of the config
+	if args.ReadOnly {
+		cfg.ReadOnly = true
+	}
+
+	// A named database from the config replaces the default one
+	if args.DBName != "" {
+		path, ok := cfg.Databases[args.DBName]
+		if !ok {
+			fmt.Printf("Error: no database named %q in the config\n", args.DBName)
+			os.Exit(1)
+		}
+		cfg.Database = path
+	}
+
 	// Connect to database
-	db, err := database.ConnectDB(cfg.Database)
+	db, err := database.ConnectDB(cfg.Database, cfg.ReadOnly)
 	if err != nil {
 		fmt.Printf("Error connecting to database: %v\n", err)
 		os.Exit(1)
@@ -39,13 +70,30 @@ func main() {
 	defer db.Close()
 
 	// Ensure database schema
-	if err := database.EnsureSchema(db); err != nil {
+	if err := database.EnsureSchema(db, cfg.ReadOnly); err != nil {
 		fmt.Printf("Error creating schema: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Write the daily snapshot if enabled
+	if err := commands.WriteDailySnapshot(db, cfg.SnapshotDir, cfg.SnapshotRetentionDays, cfg.DayCutoffHour); err != nil {
+		fmt.Printf("Error writing snapshot: %v\n", err)
+	}
+
+	// Remove tasks that have been in the trash too long
+	if cfg.TrashRetentionDays > 0 && !cfg.ReadOnly {
+		if removed, err := database.EmptyTrash(db, cfg.TrashRetentionDays); err != nil {
+			fmt.Printf("Error emptying trash: %v\n", err)
+		} else if removed > 0 {
+			utils.Log("Removed %d task(s) from the trash", removed)
+		}
+	}
+
 	// Handle CLI commands
-	if cli.HandleCommands(db, args) {
+	if stylesWarning != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", stylesWarning)
+	}
+	if cli.HandleCommands(db, cfg, args) {
 		return
 	}
 
@@ -55,11 +103,18 @@ func main() {
 
 	// Create the UI model
 	model := ui.NewModel(db, cfg, styles)
+	if stylesWarning != "" {
+		model.ShowBanner(stylesWarning)
+	}
 
 	// Create and run the Bubble Tea program
 	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	finalModel, err := p.Run()
+	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
+	if m, ok := finalModel.(ui.Model); ok {
+		m.Cleanup()
+	}
 }
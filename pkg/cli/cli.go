@@ -3,30 +3,58 @@ package cli
 import (
 	"database/sql"
 	"flag"
+	"fmt"
+	"os"
 
 	"awp/pkg/commands"
+	"awp/pkg/config"
 )
 
 // Args represents parsed command line arguments
 type Args struct {
-	ConfigPath string
-	Verbose    bool
+	ConfigPath   string
+	DBName       string
+	Verbose      bool
+	ReadOnly     bool
+	NoColor      bool
+	StrictConfig bool
 
 	// Task operations
 	AddTask  string
+	AddFile  string
 	DateFlag string
 
+	CompleteMatch string
+	ForceFlag     bool
+
+	// Task IDs given after --swap-dates
+	SwapDates   bool
+	SwapDateIDs []string
+
+	// Agenda
+	AgendaFlag bool
+	FromFlag   string
+	ToFlag     string
+	FormatFlag string
+
 	// Database operations
 	DatabaseCmd string
+	EmptyTrash  bool
 	ProjectFlag string
 	YesFlag     bool
 	DoneFlag    bool
 	UndoneFlag  bool
 
+	// Duplicate removal
+	Dedupe        bool
+	MatchProjects bool
+
 	// Import/Export operations
 	ImportFile string
 	ExportFile string
 	TypeFlag   string
+	DryRunFlag bool
+	MergeFlag  string
 }
 
 // ParseArgs parses command line arguments and returns Args struct
@@ -35,14 +63,31 @@ func ParseArgs() *Args {
 
 	// Define command line flags
 	flag.StringVar(&args.ConfigPath, "config", "", "Path to configuration file")
+	flag.StringVar(&args.DBName, "db-name", "", "Use the database configured under this name in databases")
 	flag.BoolVar(&args.Verbose, "verbose", false, "Enable verbose logging")
+	flag.BoolVar(&args.ReadOnly, "read-only", false, "Disable all changes to the database")
+	flag.BoolVar(&args.StrictConfig, "strict-config", false, "Report unknown keys in the config and styles files as errors")
+	flag.BoolVar(&args.NoColor, "no-color", false, "Disable colored output (also disabled by NO_COLOR or when not printing to a terminal)")
 
 	// Task operations
 	flag.StringVar(&args.AddTask, "add", "", "Add a new task")
+	flag.StringVar(&args.AddFile, "add-file", "", "Add one task per line of a plain text file")
 	flag.StringVar(&args.DateFlag, "date", "", "Date for task (YYYY-MM-DD format)")
+	flag.StringVar(&args.CompleteMatch, "complete-match", "", "Mark the undone task whose title contains the text as done")
+	flag.BoolVar(&args.ForceFlag, "force", false, "With --complete-match, complete every matching task")
+	flag.BoolVar(&args.SwapDates, "swap-dates", false, "Exchange the due dates of the two tasks whose IDs follow (--swap-dates 3 7)")
+
+	// Agenda
+	flag.BoolVar(&args.AgendaFlag, "agenda", false, "Print tasks day by day for a date range")
+	flag.StringVar(&args.FromFlag, "from", "", "First day of the agenda (YYYY-MM-DD, default today)")
+	flag.StringVar(&args.ToFlag, "to", "", "Last day of the agenda (YYYY-MM-DD, default a week from --from)")
+	flag.StringVar(&args.FormatFlag, "format", "txt", "Agenda output format (txt, md)")
 
 	// Database operations
 	flag.StringVar(&args.DatabaseCmd, "database", "", "Database command (purge)")
+	flag.BoolVar(&args.EmptyTrash, "empty-trash", false, "Permanently delete the tasks in the trash")
+	flag.BoolVar(&args.Dedupe, "dedupe", false, "Move tasks with the same title and due date as an earlier task to the trash")
+	flag.BoolVar(&args.MatchProjects, "match-projects", false, "With --dedupe, only treat tasks with the same projects as duplicates")
 	flag.StringVar(&args.ProjectFlag, "project", "", "Filter by project")
 	flag.BoolVar(&args.YesFlag, "yes", false, "Skip confirmation")
 	flag.BoolVar(&args.DoneFlag, "done", false, "Filter done tasks")
@@ -51,17 +96,41 @@ func ParseArgs() *Args {
 	// Import/Export operations
 	flag.StringVar(&args.ImportFile, "import", "", "Import tasks from file")
 	flag.StringVar(&args.ExportFile, "export", "", "Export tasks to file")
-	flag.StringVar(&args.TypeFlag, "type", "json", "Export file type (json, txt)")
+	flag.StringVar(&args.TypeFlag, "type", "json", "Export file type (json, txt, csv, md, ics, todotxt)")
+	flag.BoolVar(&args.DryRunFlag, "dry-run", false, "Show what --import or --dedupe would do without changing the database")
+	flag.StringVar(&args.MergeFlag, "merge", commands.MergeSkip, "How to import tasks that already exist with the same title and date (skip, replace, append)")
 
 	flag.Parse()
+	args.SwapDateIDs = flag.Args()
 	return args
 }
 
 // HandleCommands processes CLI commands and returns true if a command was handled
-func HandleCommands(db *sql.DB, args *Args) bool {
+func HandleCommands(db *sql.DB, cfg config.Config, args *Args) bool {
+	// Refuse commands that change the database in read-only mode
+	if cfg.ReadOnly && (args.AddTask != "" || args.AddFile != "" || args.CompleteMatch != "" || args.SwapDates || args.DatabaseCmd != "" || args.EmptyTrash || ((args.ImportFile != "" || args.Dedupe) && !args.DryRunFlag)) {
+		fmt.Fprintln(os.Stderr, "Read-only mode: this command would change the database")
+		os.Exit(1)
+	}
+
 	// Check for CLI commands
 	if args.AddTask != "" {
-		commands.HandleAddTask(db, args.AddTask, args.DateFlag)
+		commands.HandleAddTask(db, cfg, args.AddTask, args.DateFlag, args.YesFlag)
+		return true
+	}
+
+	if args.AddFile != "" {
+		commands.HandleAddFile(db, cfg, args.AddFile, args.DateFlag, args.YesFlag)
+		return true
+	}
+
+	if args.CompleteMatch != "" {
+		commands.HandleCompleteMatch(db, args.CompleteMatch, args.ForceFlag)
+		return true
+	}
+
+	if args.SwapDates {
+		commands.HandleSwapDates(db, args.SwapDateIDs)
 		return true
 	}
 
@@ -70,13 +139,28 @@ func HandleCommands(db *sql.DB, args *Args) bool {
 		return true
 	}
 
+	if args.EmptyTrash {
+		commands.HandleEmptyTrash(db, args.YesFlag)
+		return true
+	}
+
+	if args.Dedupe {
+		commands.HandleDedupeCommand(db, args.DryRunFlag, args.YesFlag, args.MatchProjects)
+		return true
+	}
+
 	if args.ImportFile != "" {
-		commands.HandleImportCommand(db, args.ImportFile)
+		commands.HandleImportCommand(db, args.ImportFile, args.DryRunFlag, args.YesFlag, args.MergeFlag)
+		return true
+	}
+
+	if args.AgendaFlag {
+		commands.HandleAgendaCommand(db, cfg, args.FromFlag, args.ToFlag, args.FormatFlag, commands.UseColor(args.NoColor))
 		return true
 	}
 
 	if args.ExportFile != "" {
-		commands.HandleExportCommand(db, args.ExportFile, args.TypeFlag)
+		commands.HandleExportCommand(db, args.ExportFile, args.TypeFlag, args.YesFlag)
 		return true
 	}
 
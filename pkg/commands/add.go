@@ -8,36 +8,92 @@ import (
 	"strings"
 	"time"
 
+	"awp/pkg/config"
 	"awp/pkg/database"
+	"awp/pkg/utils"
 )
 
-// HandleAddTask processes the --add command
-func HandleAddTask(db *sql.DB, taskText string, dateStr string) {
-	// Parse date
-	var dueDate time.Time
-	var err error
+// HandleAddTask processes the --add command; force adds the task even if it duplicates an existing one
+func HandleAddTask(db *sql.DB, cfg config.Config, taskText string, dateStr string, force bool) {
+	dueDate := parseAddDate(cfg, dateStr)
 
-	if dateStr != "" {
-		dueDate, err = time.Parse("2006-01-02", dateStr)
+	if _, err := addTaskText(db, cfg, taskText, dueDate, force); err != nil {
+		fmt.Fprintf(os.Stderr, "Error adding task: %v\n", err)
+		os.Exit(1)
+	}
+
+	// fmt.Printf("Task added successfully: %s\n", title)
+}
+
+// HandleAddFile processes the --add-file command, adding one task per non-empty line of the file,
+// all due on the --date day
+func HandleAddFile(db *sql.DB, cfg config.Config, filename string, dateStr string, force bool) {
+	dueDate := parseAddDate(cfg, dateStr)
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(1)
+	}
+
+	var added, total int
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		total++
+
+		ok, err := addTaskText(db, cfg, line, dueDate, force)
 		if err != nil {
-			fmt.Printf("Error parsing date: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error adding task '%s': %v\n", line, err)
+			continue
 		}
-	} else {
-		// Default to today
-		dueDate = time.Now()
+		if ok {
+			added++
+		}
+	}
+
+	fmt.Printf("Added %d of %d task(s) from %s\n", added, total, filename)
+}
+
+// parseAddDate parses the --date value for new tasks, defaulting to today
+func parseAddDate(cfg config.Config, dateStr string) time.Time {
+	if dateStr == "" {
+		return utils.Today(cfg.DayCutoffHour)
 	}
 
+	dueDate, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		fmt.Printf("Error parsing date: %v\n", err)
+		os.Exit(1)
+	}
+	return dueDate
+}
+
+// addTaskText adds a task from its text, extracting +project and @context tags. It returns
+// false without an error when the task was skipped by the past date or duplicate checks.
+func addTaskText(db *sql.DB, cfg config.Config, taskText string, dueDate time.Time, force bool) (bool, error) {
 	// Extract projects from task text (format: +project)
-	projects := extractProjects(taskText)
+	projects := database.DedupeTags(extractProjects(taskText), cfg.TagsCaseSensitive)
 
 	// Extract contexts from task text (format: @context)
-	contexts := extractContexts(taskText)
+	contexts := database.DedupeTags(extractContexts(taskText), cfg.TagsCaseSensitive)
+
+	contexts = WithProjectDefaultContexts(cfg, projects, contexts)
 
 	// Remove project and context tags from title for clean display
 	title := removeProjectTags(taskText)
 	title = removeContextTags(title)
 
+	title, truncated, err := FitTitle(cfg, title)
+	if err != nil {
+		return false, err
+	}
+	if truncated {
+		fmt.Fprintf(os.Stderr, "Warning: title truncated to %d characters\n", cfg.MaxTitleLength)
+	}
+
 	// Create task
 	task := database.TodoItem{
 		Status:      false,
@@ -48,17 +104,69 @@ func HandleAddTask(db *sql.DB, taskText string, dateStr string) {
 		Contexts:    contexts,
 	}
 
+	// Catch typos like a past year
+	if cfg.WarnPastDueDate != "" && dueDate.Format("2006-01-02") < utils.Today(cfg.DayCutoffHour).Format("2006-01-02") {
+		fmt.Fprintf(os.Stderr, "Warning: due date %s is in the past\n", dueDate.Format("2006-01-02"))
+		if cfg.WarnPastDueDate == "confirm" && !force {
+			fmt.Fprintln(os.Stderr, "Skipped. Use --yes to add it anyway.")
+			return false, nil
+		}
+	}
+
+	if cfg.WarnDuplicates {
+		dup, err := database.FindDuplicate(db, title, dueDate)
+		if err != nil {
+			return false, fmt.Errorf("checking for duplicates: %w", err)
+		}
+		if dup != nil {
+			fmt.Fprintf(os.Stderr, "Warning: task %d %q is already due on %s\n", dup.ID, dup.Title, dueDate.Format("2006-01-02"))
+			if !force {
+				fmt.Fprintln(os.Stderr, "Skipped. Use --yes to add it anyway.")
+				return false, nil
+			}
+		}
+	}
+
 	if err := database.AddTask(db, task); err != nil {
-		fmt.Printf("Error adding task: %v\n", err)
-		os.Exit(1)
+		return false, err
 	}
+	return true, nil
+}
 
-	// fmt.Printf("Task added successfully: %s\n", title)
+// FitTitle applies max_title_length to a task title. A longer title is an error, or with
+// title_overflow "truncate" is cut to the limit, reporting that it was.
+func FitTitle(cfg config.Config, title string) (string, bool, error) {
+	runes := []rune(title)
+	if cfg.MaxTitleLength <= 0 || len(runes) <= cfg.MaxTitleLength {
+		return title, false, nil
+	}
+
+	if cfg.TitleOverflow != "truncate" {
+		return "", false, fmt.Errorf("title is %d characters long, the limit is %d", len(runes), cfg.MaxTitleLength)
+	}
+	return strings.TrimSpace(string(runes[:cfg.MaxTitleLength])), true, nil
+}
+
+// WithProjectDefaultContexts returns contexts extended by the configured default contexts of the
+// projects, without duplicating contexts the task already has
+func WithProjectDefaultContexts(cfg config.Config, projects, contexts []string) []string {
+	if len(cfg.ProjectDefaultContexts) == 0 {
+		return contexts
+	}
+
+	for _, taskProject := range projects {
+		for project, defaults := range cfg.ProjectDefaultContexts {
+			if taskProject == project || (!cfg.TagsCaseSensitive && strings.EqualFold(taskProject, project)) {
+				contexts = append(contexts, defaults...)
+			}
+		}
+	}
+	return database.DedupeTags(contexts, cfg.TagsCaseSensitive)
 }
 
-// extractProjects finds all +project tags in text
+// extractProjects finds all +project tags in text, including hierarchical ones like +work/clientA
 func extractProjects(text string) []string {
-	re := regexp.MustCompile(`\+(\w+)`)
+	re := regexp.MustCompile(`\+([\w/]+)`)
 	matches := re.FindAllStringSubmatch(text, -1)
 	var projects []string
 	for _, match := range matches {
@@ -69,7 +177,7 @@ func extractProjects(text string) []string {
 
 // removeProjectTags removes +project tags from text for clean title
 func removeProjectTags(text string) string {
-	re := regexp.MustCompile(`\s*\+\w+\s*`)
+	re := regexp.MustCompile(`\s*\+[\w/]+\s*`)
 	return strings.TrimSpace(re.ReplaceAllString(text, " "))
 }
 
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"slices"
+	"testing"
+
+	"awp/pkg/config"
+)
+
+func TestWithProjectDefaultContexts(t *testing.T) {
+	defaults := map[string][]string{
+		"errands": {"out"},
+		"work":    {"office", "computer"},
+	}
+
+	tests := []struct {
+		name          string
+		caseSensitive bool
+		projects      []string
+		contexts      []string
+		want          []string
+	}{
+		{"appends the mapped context", false, []string{"errands"}, nil, []string{"out"}},
+		{"keeps existing contexts first", false, []string{"errands"}, []string{"phone"}, []string{"phone", "out"}},
+		{"does not duplicate a context", false, []string{"errands"}, []string{"out"}, []string{"out"}},
+		{"does not duplicate a context in another case", false, []string{"errands"}, []string{"Out"}, []string{"Out"}},
+		{"project in another case", false, []string{"Errands"}, nil, []string{"out"}},
+		{"project in another case, case sensitive", true, []string{"Errands"}, nil, []string{}},
+		{"several projects", false, []string{"work", "errands"}, []string{"computer"}, []string{"computer", "office", "out"}},
+		{"unmapped project", false, []string{"home"}, []string{"phone"}, []string{"phone"}},
+		{"no projects", false, nil, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Config{ProjectDefaultContexts: defaults, TagsCaseSensitive: tt.caseSensitive}
+			got := WithProjectDefaultContexts(cfg, tt.projects, tt.contexts)
+			if got == nil {
+				got = []string{}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("WithProjectDefaultContexts(%q, %q) = %q, want %q", tt.projects, tt.contexts, got, tt.want)
+			}
+		})
+	}
+
+	// Without any mapping the contexts come back untouched
+	if got := WithProjectDefaultContexts(config.Config{}, []string{"errands"}, []string{"phone"}); !slices.Equal(got, []string{"phone"}) {
+		t.Errorf("without mappings got %q", got)
+	}
+}
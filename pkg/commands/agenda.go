@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"awp/pkg/config"
+	"awp/pkg/database"
+	"awp/pkg/utils"
+)
+
+// HandleAgendaCommand processes the --agenda command, printing the tasks from fromStr to toStr
+// (YYYY-MM-DD, defaulting to today and a week from the start) day by day as txt or md; color
+// highlights dates and tags in txt output
+func HandleAgendaCommand(db *sql.DB, cfg config.Config, fromStr, toStr, format string, color bool) {
+	if format != "txt" && format != "md" {
+		fmt.Printf("Unknown agenda format: %s (use txt or md)\n", format)
+		os.Exit(1)
+	}
+
+	from := utils.DateOnly(utils.Today(cfg.DayCutoffHour))
+	if fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			fmt.Printf("Error parsing --from date: %v\n", err)
+			os.Exit(1)
+		}
+		from = parsed
+	}
+
+	to := from.AddDate(0, 0, 6)
+	if toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			fmt.Printf("Error parsing --to date: %v\n", err)
+			os.Exit(1)
+		}
+		to = parsed
+	}
+
+	if to.Before(from) {
+		fmt.Println("--to must not be before --from")
+		os.Exit(1)
+	}
+
+	whereClause, args := database.BuildDateRangeClause(from, to)
+	tasks, err := database.LoadTasksSorted(db, whereClause, "duedate ASC, id ASC", args...)
+	if err != nil {
+		fmt.Printf("Error loading tasks: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(FormatAgenda(tasks, from, to, format, color && format == "txt"))
+}
+
+// FormatAgenda renders tasks day by day from from to to, listing "(none)" under days without tasks.
+// With color, day headers are bold and tags are colored.
+func FormatAgenda(tasks []database.TodoItem, from, to time.Time, format string, color bool) string {
+	byDay := make(map[string][]database.TodoItem)
+	for _, task := range tasks {
+		day := task.DueDate.Format("2006-01-02")
+		byDay[day] = append(byDay[day], task)
+	}
+
+	var lines []string
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dateStr := day.Format("2006-01-02")
+		header := fmt.Sprintf("%s (%s)", dateStr, day.Weekday())
+
+		if format == "md" {
+			lines = append(lines, "## "+header, "")
+		} else if color {
+			lines = append(lines, boldText(header+":"))
+		} else {
+			lines = append(lines, header+":")
+		}
+
+		if len(byDay[dateStr]) == 0 {
+			if format == "md" {
+				lines = append(lines, "_(none)_")
+			} else {
+				lines = append(lines, "  (none)")
+			}
+		}
+
+		for _, task := range byDay[dateStr] {
+			status := " "
+			switch task.State {
+			case database.StateInProgress:
+				status = "~"
+			case database.StateDone:
+				status = "x"
+			}
+
+			text := taskText(task)
+			if color {
+				text = colorTags(text)
+			}
+
+			if format == "md" {
+				lines = append(lines, fmt.Sprintf("- [%s] %s", status, text))
+			} else {
+				lines = append(lines, fmt.Sprintf("  [%s] %s", status, text))
+			}
+		}
+		lines = append(lines, "")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
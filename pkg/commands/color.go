@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"os"
+	"regexp"
+
+	"github.com/muesli/termenv"
+)
+
+// Colors of CLI output, matching the default TUI project and context colors
+const (
+	cliProjectColor = "2"
+	cliContextColor = "4"
+)
+
+// cliTagRegex matches +project and @context tags in task text
+var cliTagRegex = regexp.MustCompile(`[+@][\w/]+`)
+
+// UseColor reports whether CLI output should be colored: only when stdout is a terminal,
+// NO_COLOR is not set and noColor (--no-color) is false
+func UseColor(noColor bool) bool {
+	if noColor {
+		return false
+	}
+	return termenv.NewOutput(os.Stdout).EnvColorProfile() != termenv.Ascii
+}
+
+// colorTags colors +project and @context tags in text
+func colorTags(text string) string {
+	return cliTagRegex.ReplaceAllStringFunc(text, func(tag string) string {
+		color := cliProjectColor
+		if tag[0] == '@' {
+			color = cliContextColor
+		}
+		return termenv.String(tag).Foreground(termenv.ANSI256.Color(color)).String()
+	})
+}
+
+// boldText renders text in bold
+func boldText(text string) string {
+	return termenv.String(text).Bold().String()
+}
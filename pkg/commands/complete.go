@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"awp/pkg/database"
+)
+
+// HandleCompleteMatch processes the --complete-match command. It marks the single undone task whose
+// title contains text as done; when several match, it lists them unless force completes them all.
+func HandleCompleteMatch(db *sql.DB, text string, force bool) {
+	tasks, err := database.FindTasksByTitleLike(db, text)
+	if err != nil {
+		fmt.Printf("Error finding tasks: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(tasks) == 0 {
+		fmt.Fprintf(os.Stderr, "No undone task matches %q\n", text)
+		os.Exit(1)
+	}
+
+	if len(tasks) > 1 && !force {
+		fmt.Fprintf(os.Stderr, "%d undone tasks match %q:\n", len(tasks), text)
+		for _, task := range tasks {
+			fmt.Fprintf(os.Stderr, "  %d  %s  %s\n", task.ID, task.DueDate.Format("2006-01-02"), task.Title)
+		}
+		fmt.Fprintln(os.Stderr, "Use --force to complete all of them.")
+		os.Exit(1)
+	}
+
+	for _, task := range tasks {
+		if err := database.UpdateTaskStatus(db, task.ID, true); err != nil {
+			fmt.Printf("Error completing task %d: %v\n", task.ID, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Completed task %d: %s\n", task.ID, task.Title)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"awp/pkg/database"
 )
 
 // HandleDatabaseCommand processes --database commands
@@ -15,7 +17,7 @@ func HandleDatabaseCommand(db *sql.DB, cmd, dateStr, projectStr string, skipConf
 	}
 
 	// Build where clause for deletion
-	whereClause := buildPurgeWhereClause(dateStr, projectStr, doneOnly, undoneOnly)
+	whereClause, args := buildPurgeWhereClause(dateStr, projectStr, doneOnly, undoneOnly)
 
 	// Show confirmation unless --yes flag is used
 	if !skipConfirm {
@@ -34,7 +36,7 @@ func HandleDatabaseCommand(db *sql.DB, cmd, dateStr, projectStr string, skipConf
 		query += " WHERE " + whereClause
 	}
 
-	result, err := db.Exec(query)
+	result, err := db.Exec(query, args...)
 	if err != nil {
 		fmt.Printf("Error purging tasks: %v\n", err)
 		os.Exit(1)
@@ -44,16 +46,49 @@ func HandleDatabaseCommand(db *sql.DB, cmd, dateStr, projectStr string, skipConf
 	fmt.Printf("Successfully deleted %d task(s)\n", rowsAffected)
 }
 
-// buildPurgeWhereClause builds WHERE clause for purge operations
-func buildPurgeWhereClause(dateStr, projectStr string, doneOnly, undoneOnly bool) string {
+// HandleEmptyTrash processes the --empty-trash command, permanently removing every task in the trash
+func HandleEmptyTrash(db *sql.DB, skipConfirm bool) {
+	trash, err := database.LoadTrash(db)
+	if err != nil {
+		fmt.Printf("Error loading trash: %v\n", err)
+		os.Exit(1)
+	}
+	if len(trash) == 0 {
+		fmt.Println("The trash is empty.")
+		return
+	}
+
+	if !skipConfirm {
+		fmt.Printf("Permanently delete %d task(s) in the trash? (y/N): ", len(trash))
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Operation cancelled.")
+			return
+		}
+	}
+
+	removed, err := database.EmptyTrash(db, 0)
+	if err != nil {
+		fmt.Printf("Error emptying trash: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Permanently deleted %d task(s)\n", removed)
+}
+
+// buildPurgeWhereClause builds WHERE clause for purge operations and its arguments
+func buildPurgeWhereClause(dateStr, projectStr string, doneOnly, undoneOnly bool) (string, []interface{}) {
 	var conditions []string
+	var args []interface{}
 
 	if dateStr != "" {
-		conditions = append(conditions, fmt.Sprintf("date(duedate) = date('%s')", dateStr))
+		conditions = append(conditions, "date(duedate) = date(?)")
+		args = append(args, dateStr)
 	}
 
 	if projectStr != "" {
-		conditions = append(conditions, fmt.Sprintf("projects LIKE '%%%s%%'", projectStr))
+		conditions = append(conditions, "projects LIKE ?")
+		args = append(args, "%"+projectStr+"%")
 	}
 
 	if doneOnly {
@@ -62,5 +97,5 @@ func buildPurgeWhereClause(dateStr, projectStr string, doneOnly, undoneOnly bool
 		conditions = append(conditions, "status = 0")
 	}
 
-	return strings.Join(conditions, " AND ")
+	return strings.Join(conditions, " AND "), args
 }
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+
+	"awp/pkg/database"
+)
+
+// HandleDedupeCommand processes the --dedupe command, moving tasks that repeat the title and due date
+// (and with matchProjects the projects) of an earlier task to the trash. The earliest task is kept.
+func HandleDedupeCommand(db *sql.DB, dryRun, skipConfirm, matchProjects bool) {
+	duplicates, err := database.FindDuplicateTasks(db, matchProjects)
+	if err != nil {
+		fmt.Printf("Error finding duplicates: %v\n", err)
+		os.Exit(1)
+	}
+	if len(duplicates) == 0 {
+		fmt.Println("No duplicate tasks found.")
+		return
+	}
+
+	if dryRun {
+		fmt.Printf("Would remove %d duplicate task(s):\n", len(duplicates))
+		for _, task := range duplicates {
+			fmt.Printf("  %d  %s  %s\n", task.ID, formatDueDate(task.DueDate), task.Title)
+		}
+		return
+	}
+
+	// Show confirmation unless --yes flag is used
+	if !skipConfirm {
+		fmt.Printf("Move %d duplicate task(s) to the trash? (y/N): ", len(duplicates))
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Operation cancelled.")
+			return
+		}
+	}
+
+	ids := make([]int, len(duplicates))
+	for i, task := range duplicates {
+		ids[i] = task.ID
+	}
+	if err := database.DeleteTasks(db, ids); err != nil {
+		fmt.Printf("Error removing duplicates: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Moved %d duplicate task(s) to the trash\n", len(duplicates))
+}
@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,10 +11,22 @@ import (
 	"strings"
 
 	"awp/pkg/database"
+	"awp/pkg/utils"
 )
 
-// HandleExportCommand processes --export commands
-func HandleExportCommand(db *sql.DB, filename, exportType string) {
+// HandleExportCommand processes --export commands. An existing file is only overwritten after
+// confirmation, unless skipConfirm is set.
+func HandleExportCommand(db *sql.DB, filename, exportType string, skipConfirm bool) {
+	if _, err := os.Stat(filename); err == nil && !skipConfirm {
+		fmt.Printf("File exists, overwrite? (y/N): ")
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Operation cancelled.")
+			return
+		}
+	}
+
 	// Load all tasks
 	tasks, err := database.LoadTasks(db, "")
 	if err != nil {
@@ -27,41 +41,225 @@ func HandleExportCommand(db *sql.DB, filename, exportType string) {
 		os.Exit(1)
 	}
 
-	var content []byte
+	content, err := FormatTasks(tasks, exportType)
+	if err != nil {
+		fmt.Printf("Error exporting tasks: %v\n", err)
+		os.Exit(1)
+	}
 
+	if err := os.WriteFile(filename, content, 0644); err != nil {
+		fmt.Printf("Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Successfully exported %d task(s) to %s\n", len(tasks), filename)
+}
+
+// ExportTypes lists the supported export types
+var ExportTypes = []string{"json", "txt", "csv", "md", "ics", "todotxt"}
+
+// FormatTasks renders tasks in the given export type
+func FormatTasks(tasks []database.TodoItem, exportType string) ([]byte, error) {
 	switch exportType {
 	case "json":
-		content, err = json.MarshalIndent(tasks, "", "  ")
-		if err != nil {
-			fmt.Printf("Error marshaling tasks to JSON: %v\n", err)
-			os.Exit(1)
-		}
+		return FormatTasksJSON(tasks)
 	case "txt":
-		var lines []string
-		var lastDate string
-		for _, task := range tasks {
-			dateStr := task.DueDate.Format("02.01.2006")
-			if dateStr != lastDate {
-				lines = append(lines, fmt.Sprintf("\n%s:", dateStr))
-				lastDate = dateStr
+		return []byte(FormatTasksTxt(tasks)), nil
+	case "csv":
+		return FormatTasksCSV(tasks)
+	case "md":
+		return []byte(FormatTasksMarkdown(tasks)), nil
+	case "ics":
+		return []byte(FormatTasksICS(tasks)), nil
+	case "todotxt":
+		return []byte(FormatTasksTodoTxt(tasks)), nil
+	default:
+		return nil, fmt.Errorf("unknown export type: %s", exportType)
+	}
+}
+
+// ExportFileExtension returns the file extension used for an export type
+func ExportFileExtension(exportType string) string {
+	if exportType == "todotxt" {
+		return "txt"
+	}
+	return exportType
+}
+
+// FormatTasksJSON renders tasks as indented JSON
+func FormatTasksJSON(tasks []database.TodoItem) ([]byte, error) {
+	return json.MarshalIndent(tasks, "", "  ")
+}
+
+// FormatTasksTxt renders tasks as a plain text list grouped by due date
+func FormatTasksTxt(tasks []database.TodoItem) string {
+	var lines []string
+	var lastDate string
+	for _, task := range tasks {
+		dateStr := task.DueDate.Format("02.01.2006")
+		if dateStr != lastDate {
+			lines = append(lines, fmt.Sprintf("\n%s:", dateStr))
+			lastDate = dateStr
+		}
+
+		status := " "
+		switch task.State {
+		case database.StateInProgress:
+			status = "~"
+		case database.StateDone:
+			status = "x"
+		}
+		lines = append(lines, fmt.Sprintf("- [%s] %s", status, task.Description))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// FormatTasksMarkdown renders tasks as a markdown checklist grouped by due date
+func FormatTasksMarkdown(tasks []database.TodoItem) string {
+	var lines []string
+	var lastDate string
+	for _, task := range tasks {
+		dateStr := task.DueDate.Format("2006-01-02")
+		if dateStr != lastDate {
+			if lastDate != "" {
+				lines = append(lines, "")
 			}
+			lines = append(lines, fmt.Sprintf("### %s", dateStr), "")
+			lastDate = dateStr
+		}
 
-			status := " "
-			if task.Status {
-				status = "x"
+		// Markdown checklists only know open and checked items, so in progress stays open
+		status := " "
+		if task.State == database.StateDone {
+			status = "x"
+		}
+		lines = append(lines, fmt.Sprintf("- [%s] %s", status, taskText(task)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatTasksTodoTxt renders tasks in the todo.txt format, one task per line. todo.txt has no
+// in progress state, so those tasks are written as open tasks.
+func FormatTasksTodoTxt(tasks []database.TodoItem) string {
+	var lines []string
+	for _, task := range tasks {
+		var parts []string
+		if task.State == database.StateDone {
+			parts = append(parts, "x")
+		}
+		parts = append(parts, taskText(task))
+
+		// Make sure every tag is present even if the text doesn't carry it
+		for _, project := range task.Projects {
+			if !strings.Contains(taskText(task), "+"+project) {
+				parts = append(parts, "+"+project)
 			}
-			lines = append(lines, fmt.Sprintf("- [%s] %s", status, task.Description))
 		}
-		content = []byte(strings.TrimSpace(strings.Join(lines, "\n")))
-	default:
-		fmt.Printf("Unknown export type: %s\n", exportType)
-		os.Exit(1)
+		for _, context := range task.Contexts {
+			if !strings.Contains(taskText(task), "@"+context) {
+				parts = append(parts, "@"+context)
+			}
+		}
+
+		if !task.DueDate.IsZero() {
+			parts = append(parts, "due:"+task.DueDate.Format("2006-01-02"))
+		}
+		lines = append(lines, strings.Join(parts, " "))
 	}
+	return strings.Join(lines, "\n")
+}
 
-	if err := os.WriteFile(filename, content, 0644); err != nil {
-		fmt.Printf("Error writing file: %v\n", err)
-		os.Exit(1)
+// FormatTasksCSV renders tasks as CSV with a header row
+func FormatTasksCSV(tasks []database.TodoItem) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{"id", "state", "title", "description", "due", "created", "projects", "contexts", "priority"}); err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		due := ""
+		if !task.DueDate.IsZero() {
+			due = task.DueDate.Format("2006-01-02")
+		}
+		record := []string{
+			fmt.Sprintf("%d", task.ID),
+			taskStateName(task.State),
+			task.Title,
+			task.Description,
+			due,
+			task.Created.Format("2006-01-02 15:04:05"),
+			strings.Join(task.Projects, ","),
+			strings.Join(task.Contexts, ","),
+			fmt.Sprintf("%d", task.Priority),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
 	}
 
-	fmt.Printf("Successfully exported %d task(s) to %s\n", len(tasks), filename)
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// FormatTasksICS renders tasks as an iCalendar file of VTODO entries
+func FormatTasksICS(tasks []database.TodoItem) string {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//awp//todo//EN"}
+	stamp := utils.Now().UTC().Format("20060102T150405Z")
+
+	for _, task := range tasks {
+		lines = append(lines,
+			"BEGIN:VTODO",
+			fmt.Sprintf("UID:awp-%d", task.ID),
+			"DTSTAMP:"+stamp,
+			"SUMMARY:"+escapeICS(task.Title),
+		)
+		if task.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeICS(task.Description))
+		}
+		if !task.DueDate.IsZero() {
+			lines = append(lines, "DUE;VALUE=DATE:"+task.DueDate.Format("20060102"))
+		}
+		if len(task.Projects) > 0 {
+			lines = append(lines, "CATEGORIES:"+escapeICS(strings.Join(task.Projects, ",")))
+		}
+
+		switch task.State {
+		case database.StateDone:
+			lines = append(lines, "STATUS:COMPLETED")
+		case database.StateInProgress:
+			lines = append(lines, "STATUS:IN-PROCESS")
+		default:
+			lines = append(lines, "STATUS:NEEDS-ACTION")
+		}
+		lines = append(lines, "END:VTODO")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+// escapeICS escapes text for an iCalendar property value
+func escapeICS(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// taskStateName returns the lower-case name of a task state
+func taskStateName(state database.TaskState) string {
+	switch state {
+	case database.StateInProgress:
+		return "in progress"
+	case database.StateDone:
+		return "done"
+	default:
+		return "todo"
+	}
+}
+
+// taskText returns the full text of a task, falling back to the title without a description
+func taskText(task database.TodoItem) string {
+	if task.Description != "" {
+		return task.Description
+	}
+	return task.Title
 }
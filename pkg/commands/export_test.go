@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"awp/pkg/database"
+)
+
+// newTestDB opens an in-memory database with the current schema and the given tasks
+func newTestDB(t *testing.T, tasks ...database.TodoItem) *sql.DB {
+	t.Helper()
+
+	db, err := database.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, task := range tasks {
+		if err := database.AddTask(db, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// withStdio runs fn with input on stdin and returns what it printed
+func withStdio(t *testing.T, input string, fn func()) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	stdinPath, stdoutPath := filepath.Join(dir, "stdin"), filepath.Join(dir, "stdout")
+	if err := os.WriteFile(stdinPath, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(stdinPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	stdout, err := os.Create(stdoutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+
+	prevStdin, prevStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdin, stdout
+	defer func() { os.Stdin, os.Stdout = prevStdin, prevStdout }()
+	fn()
+
+	output, err := os.ReadFile(stdoutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output)
+}
+
+func stateTasks() []database.TodoItem {
+	due := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+	return []database.TodoItem{
+		{Title: "open", Description: "open", DueDate: due, State: database.StateTodo},
+		{Title: "started", Description: "started", DueDate: due, State: database.StateInProgress},
+		{Title: "finished", Description: "finished", DueDate: due, State: database.StateDone},
+	}
+}
+
+func TestFormatTasksStates(t *testing.T) {
+	tests := []struct {
+		name   string
+		format func([]database.TodoItem) string
+		want   []string
+	}{
+		{"txt", FormatTasksTxt, []string{"- [ ] open", "- [~] started", "- [x] finished"}},
+		{"markdown", FormatTasksMarkdown, []string{"- [ ] open", "- [ ] started", "- [x] finished"}},
+		{"todotxt", FormatTasksTodoTxt, []string{"open due:2026-10-17", "started due:2026-10-17", "x finished due:2026-10-17"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.format(stateTasks())
+			for _, line := range tt.want {
+				if !containsLine(got, line) {
+					t.Errorf("output has no line %q:\n%s", line, got)
+				}
+			}
+		})
+	}
+}
+
+func containsLine(text, line string) bool {
+	for _, l := range strings.Split(text, "\n") {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFormatTasksTxt(t *testing.T) {
+	first := time.Date(2026, 10, 17, 0, 0, 0, 0, time.Local)
+	tasks := []database.TodoItem{
+		{Title: "Call Bob", Description: "Call Bob +work", DueDate: first},
+		{Title: "Title only", DueDate: first},
+		{Title: "Pay rent", Description: "Pay rent", DueDate: first.AddDate(0, 0, 1), State: database.StateDone},
+	}
+
+	// Same as the txt export always wrote: descriptions only, no title fallback
+	want := "17.10.2026:\n- [ ] Call Bob +work\n- [ ] \n\n18.10.2026:\n- [x] Pay rent"
+	if got := FormatTasksTxt(tasks); got != want {
+		t.Errorf("FormatTasksTxt() = %q, want %q", got, want)
+	}
+}
+
+func TestExportOverwritePrompt(t *testing.T) {
+	db := newTestDB(t, database.TodoItem{Title: "new", Description: "new", DueDate: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)})
+	const original = "previous backup"
+
+	tests := []struct {
+		name        string
+		input       string
+		skipConfirm bool
+		overwritten bool
+	}{
+		{"declined", "n\n", false, false},
+		{"no answer", "\n", false, false},
+		{"confirmed", "y\n", false, true},
+		{"confirmed in full", "YES\n", false, true},
+		{"--yes", "", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tasks.txt")
+			if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			output := withStdio(t, tt.input, func() { HandleExportCommand(db, path, "txt", tt.skipConfirm) })
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if overwritten := string(data) != original; overwritten != tt.overwritten {
+				t.Errorf("file overwritten = %v, want %v; it holds %q", overwritten, tt.overwritten, data)
+			}
+			if prompted := strings.Contains(output, "File exists, overwrite?"); prompted == tt.skipConfirm {
+				t.Errorf("prompted = %v with skipConfirm %v", prompted, tt.skipConfirm)
+			}
+		})
+	}
+}
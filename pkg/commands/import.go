@@ -12,26 +12,119 @@ import (
 	"awp/pkg/database"
 )
 
+// importedTask is a task parsed from an import file along with the line it came from
+type importedTask struct {
+	line int
+	task database.TodoItem
+}
+
+// Merge strategies for tasks that already exist with the same title and due date
+const (
+	MergeSkip    = "skip"    // Leave the existing task alone
+	MergeReplace = "replace" // Overwrite the existing task with the imported one
+	MergeAppend  = "append"  // Add the imported task anyway
+)
+
 // HandleImportCommand processes --import commands
-func HandleImportCommand(db *sql.DB, filename string) {
+func HandleImportCommand(db *sql.DB, filename string, dryRun, skipConfirm bool, merge string) {
+	if merge != MergeSkip && merge != MergeReplace && merge != MergeAppend {
+		fmt.Printf("Unknown merge strategy: %s (use skip, replace or append)\n", merge)
+		os.Exit(1)
+	}
+
 	content, err := os.ReadFile(filename)
 	if err != nil {
 		fmt.Printf("Error reading file: %v\n", err)
 		os.Exit(1)
 	}
 
-	lines := strings.Split(string(content), "\n")
+	tasks, problems := parseImportFile(string(content))
+
+	// Report lines that could not be understood
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+
+	if dryRun {
+		printImportPreview(db, tasks, filename, merge)
+		return
+	}
+
+	if len(tasks) == 0 {
+		fmt.Printf("No tasks found in %s\n", filename)
+		return
+	}
+
+	// Show confirmation unless --yes flag is used
+	if !skipConfirm {
+		fmt.Printf("Import %d task(s) from %s? (y/N): ", len(tasks), filename)
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Operation cancelled.")
+			return
+		}
+	}
+
+	var added, updated, skipped int
+	for _, imported := range tasks {
+		existing, err := existingImportMatch(db, imported.task, merge)
+		if err != nil {
+			fmt.Printf("Error looking up task '%s' (line %d): %v\n", imported.task.Title, imported.line, err)
+			continue
+		}
+
+		switch {
+		case existing == nil:
+			if err := database.AddTask(db, imported.task); err != nil {
+				fmt.Printf("Error adding task '%s' (line %d): %v\n", imported.task.Title, imported.line, err)
+				continue
+			}
+			added++
+		case merge == MergeReplace:
+			imported.task.ID = existing.ID
+			imported.task.Priority = existing.Priority
+			imported.task.Pinned = existing.Pinned
+			if err := database.UpdateTask(db, imported.task); err != nil {
+				fmt.Printf("Error updating task '%s' (line %d): %v\n", imported.task.Title, imported.line, err)
+				continue
+			}
+			updated++
+		default:
+			skipped++
+		}
+	}
+
+	fmt.Printf("Imported from %s: %d added, %d updated, %d skipped\n", filename, added, updated, skipped)
+}
+
+// existingImportMatch returns the task an imported task collides with under the merge strategy, if any
+func existingImportMatch(db *sql.DB, task database.TodoItem, merge string) (*database.TodoItem, error) {
+	if merge == MergeAppend {
+		return nil, nil
+	}
+	return database.FindTaskByTitleDate(db, task.Title, task.DueDate)
+}
+
+// parseImportFile parses the dated task list format and returns the tasks found together
+// with a description of every line that could not be parsed
+func parseImportFile(content string) ([]importedTask, []string) {
+	// Date lines look like DD.MM.YYYY: or YYYY-MM-DD:
+	dateRegex := regexp.MustCompile(`(?:(\d{2})\.(\d{2})\.(\d{4})|(\d{4})-(\d{2})-(\d{2})):?`)
+
+	lines := strings.Split(content, "\n")
 	var currentDate time.Time
-	var tasksAdded int
+	var tasks []importedTask
+	var problems []string
 
-	for _, line := range lines {
+	for i, line := range lines {
+		lineNo := i + 1
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
 		}
 
 		// Check if line contains a date (DD.MM.YYYY: or YYYY-MM-DD: format)
-		dateRegex := regexp.MustCompile(`(?:(\d{2})\.(\d{2})\.(\d{4})|(\d{4})-(\d{2})-(\d{2})):?`)
 		if dateMatch := dateRegex.FindStringSubmatch(line); dateMatch != nil {
 			var day, month, year int
 			if dateMatch[1] != "" {
@@ -47,46 +140,80 @@ func HandleImportCommand(db *sql.DB, filename string) {
 			continue
 		}
 
-		// Check if line is a task (starts with -)
-		if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, " - ") {
-			taskText := strings.TrimPrefix(strings.TrimSpace(line), "- ")
-			if taskText == "" {
-				continue
-			}
+		// Anything else must be a task (starts with -)
+		if !strings.HasPrefix(line, "-") {
+			problems = append(problems, fmt.Sprintf("line %d: not a date or task, skipped: %s", lineNo, line))
+			continue
+		}
 
-			status := false
-			if strings.HasPrefix(taskText, "[x]") {
-				status = true
-				taskText = strings.TrimSpace(strings.TrimPrefix(taskText, "[x]"))
-			} else if strings.HasPrefix(taskText, "[ ]") {
-				status = false
-				taskText = strings.TrimSpace(strings.TrimPrefix(taskText, "[ ]"))
-			}
+		taskText := strings.TrimSpace(strings.TrimPrefix(line, "-"))
+		if taskText == "" {
+			problems = append(problems, fmt.Sprintf("line %d: empty task, skipped", lineNo))
+			continue
+		}
 
-			// Extract projects and contexts
-			projects := extractProjects(taskText)
-			contexts := extractContexts(taskText)
-
-			// Clean title
-			title := removeProjectTags(taskText)
-			title = removeContextTags(title)
-
-			task := database.TodoItem{
-				Status:      status,
-				Title:       title,
-				Description: taskText,
-				DueDate:     currentDate,
-				Projects:    projects,
-				Contexts:    contexts,
-			}
+		state := database.StateTodo
+		if strings.HasPrefix(taskText, "[x]") {
+			state = database.StateDone
+			taskText = strings.TrimSpace(strings.TrimPrefix(taskText, "[x]"))
+		} else if strings.HasPrefix(taskText, "[~]") {
+			state = database.StateInProgress
+			taskText = strings.TrimSpace(strings.TrimPrefix(taskText, "[~]"))
+		} else if strings.HasPrefix(taskText, "[ ]") {
+			taskText = strings.TrimSpace(strings.TrimPrefix(taskText, "[ ]"))
+		}
 
-			if err := database.AddTask(db, task); err != nil {
-				fmt.Printf("Error adding task '%s': %v\n", title, err)
-				continue
-			}
-			tasksAdded++
+		if currentDate.IsZero() {
+			problems = append(problems, fmt.Sprintf("line %d: task appears before any date, it will have no due date", lineNo))
 		}
+
+		// Extract projects and contexts
+		projects := extractProjects(taskText)
+		contexts := extractContexts(taskText)
+
+		// Clean title
+		title := removeProjectTags(taskText)
+		title = removeContextTags(title)
+
+		task := database.TodoItem{
+			Title:       title,
+			Description: taskText,
+			DueDate:     currentDate,
+			Projects:    projects,
+			Contexts:    contexts,
+		}
+		task.SetState(state)
+
+		tasks = append(tasks, importedTask{line: lineNo, task: task})
 	}
 
-	fmt.Printf("Successfully imported %d task(s) from %s\n", tasksAdded, filename)
+	return tasks, problems
+}
+
+// printImportPreview lists the tasks an import would add, grouped by due date, noting
+// tasks the merge strategy would update or skip
+func printImportPreview(db *sql.DB, tasks []importedTask, filename string, merge string) {
+	fmt.Printf("Would import %d task(s) from %s\n", len(tasks), filename)
+
+	var lastDate string
+	for _, imported := range tasks {
+		dateStr := "(no date)"
+		if !imported.task.DueDate.IsZero() {
+			dateStr = imported.task.DueDate.Format("2006-01-02")
+		}
+		if dateStr != lastDate {
+			fmt.Printf("\n%s:\n", dateStr)
+			lastDate = dateStr
+		}
+
+		note := ""
+		if existing, err := existingImportMatch(db, imported.task, merge); err == nil && existing != nil {
+			if merge == MergeReplace {
+				note = fmt.Sprintf(" (updates task %d)", existing.ID)
+			} else {
+				note = fmt.Sprintf(" (exists as task %d, skipped)", existing.ID)
+			}
+		}
+		fmt.Printf("  - %s%s\n", imported.task.Title, note)
+	}
 }
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"awp/pkg/database"
+	"awp/pkg/utils"
+)
+
+const (
+	snapshotPrefix     = "awp-snapshot-"
+	snapshotSuffix     = ".json"
+	snapshotDateFormat = "2006-01-02"
+)
+
+// WriteDailySnapshot writes a JSON snapshot of all tasks to dir unless one exists for today, with
+// days starting at cutoffHour in the configured time zone, then prunes snapshots older than
+// retentionDays. An empty dir disables snapshots.
+func WriteDailySnapshot(db *sql.DB, dir string, retentionDays, cutoffHour int) error {
+	if dir == "" {
+		return nil
+	}
+
+	dir, err := utils.ExpandHome(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	today := utils.Today(cutoffHour)
+	snapshotPath := filepath.Join(dir, snapshotPrefix+today.Format(snapshotDateFormat)+snapshotSuffix)
+
+	// Only one snapshot per day
+	if _, err := os.Stat(snapshotPath); os.IsNotExist(err) {
+		tasks, err := database.LoadTasks(db, "")
+		if err != nil {
+			return err
+		}
+
+		content, err := FormatTasksJSON(tasks)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(snapshotPath, content, 0644); err != nil {
+			return err
+		}
+		utils.Log("Wrote snapshot: %s", snapshotPath)
+	} else if err != nil {
+		return err
+	}
+
+	return pruneSnapshots(dir, today, retentionDays)
+}
+
+// pruneSnapshots removes snapshots in dir that are older than retentionDays
+func pruneSnapshots(dir string, today time.Time, retentionDays int) error {
+	if retentionDays <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, utils.Location()).AddDate(0, 0, -retentionDays)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+			continue
+		}
+
+		dateStr := strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), snapshotSuffix)
+		snapshotDate, err := time.ParseInLocation(snapshotDateFormat, dateStr, utils.Location())
+		if err != nil {
+			continue
+		}
+
+		if snapshotDate.Before(cutoff) {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return err
+			}
+			utils.Log("Pruned snapshot: %s", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"awp/pkg/utils"
+)
+
+func TestWriteDailySnapshotDay(t *testing.T) {
+	prevNow, prevLocation := utils.Now, utils.Location()
+	t.Cleanup(func() {
+		utils.Now = prevNow
+		utils.SetLocation(prevLocation)
+	})
+	zone := time.FixedZone("UTC-7", -7*3600)
+	utils.SetLocation(zone)
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		cutoffHour int
+		want       []string
+	}{
+		// Today's snapshot is written and those more than a week older are pruned. It is already
+		// the 18th in UTC, but still the 17th in the configured zone.
+		{"time zone", time.Date(2026, 10, 18, 1, 30, 0, 0, time.UTC), 0, []string{"awp-snapshot-2026-10-10.json", "awp-snapshot-2026-10-11.json", "awp-snapshot-2026-10-17.json"}},
+		{"before the cutoff", time.Date(2026, 10, 18, 2, 0, 0, 0, zone), 3, []string{"awp-snapshot-2026-10-10.json", "awp-snapshot-2026-10-11.json", "awp-snapshot-2026-10-17.json"}},
+		{"after the cutoff", time.Date(2026, 10, 18, 3, 0, 0, 0, zone), 3, []string{"awp-snapshot-2026-10-11.json", "awp-snapshot-2026-10-18.json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"awp-snapshot-2026-10-09.json", "awp-snapshot-2026-10-10.json", "awp-snapshot-2026-10-11.json"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("[]"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			utils.Now = func() time.Time { return tt.now }
+
+			if err := WriteDailySnapshot(newTestDB(t), dir, 7, tt.cutoffHour); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Name())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("snapshots = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
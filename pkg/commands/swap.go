@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"awp/pkg/database"
+)
+
+// HandleSwapDates processes the --swap-dates command, exchanging the due dates of the two tasks
+// whose IDs are given
+func HandleSwapDates(db *sql.DB, ids []string) {
+	if len(ids) != 2 {
+		fmt.Fprintln(os.Stderr, "--swap-dates needs two task IDs, e.g. --swap-dates 3 7")
+		os.Exit(1)
+	}
+
+	var tasks []*database.TodoItem
+	for _, arg := range ids {
+		id, err := strconv.Atoi(arg)
+		if err != nil || id <= 0 {
+			fmt.Fprintf(os.Stderr, "Invalid task ID %q\n", arg)
+			os.Exit(1)
+		}
+
+		task, err := database.LoadTaskByID(db, id)
+		if err != nil {
+			fmt.Printf("Error loading task %d: %v\n", id, err)
+			os.Exit(1)
+		}
+		if task == nil {
+			fmt.Fprintf(os.Stderr, "No task with ID %d\n", id)
+			os.Exit(1)
+		}
+		tasks = append(tasks, task)
+	}
+
+	first, second := tasks[0], tasks[1]
+	if first.ID == second.ID {
+		fmt.Printf("Task %d swapped with itself - nothing changed\n", first.ID)
+		return
+	}
+
+	if err := database.SwapDueDates(db, *first, *second); err != nil {
+		fmt.Printf("Error swapping due dates: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Task %d (%s) is now due: %s\n", first.ID, first.Title, formatDueDate(second.DueDate))
+	fmt.Printf("Task %d (%s) is now due: %s\n", second.ID, second.Title, formatDueDate(first.DueDate))
+}
+
+// formatDueDate renders a due date as YYYY-MM-DD, or "no date" when it is not set
+func formatDueDate(date time.Time) string {
+	if date.IsZero() {
+		return "no date"
+	}
+	return date.Format("2006-01-02")
+}
@@ -1,12 +1,18 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 
 	"awp/pkg/keymaps"
+	"awp/pkg/utils"
 )
 
 // Config holds the application configuration who'd thought
@@ -14,6 +20,170 @@ type Config struct {
 	Database   string            `json:"database"`
 	KeyMap     map[string]string `json:"keymap"`
 	StylesFile string            `json:"styles_file"`
+
+	// Databases names separate task lists by their database path, e.g. {"work": "~/work.db"}, to
+	// switch between at runtime or pick with --db-name
+	Databases map[string]string `json:"databases"`
+
+	// ShareTarget selects where the share action sends tasks ("clipboard" or "mailto")
+	ShareTarget string `json:"share_target"`
+
+	// ExportDir is where exports from the TUI are written (current directory when empty)
+	ExportDir string `json:"export_dir"`
+
+	// OpenViewFormat is the format ("md" or "json") the view is written in before opening it
+	OpenViewFormat string `json:"open_view_format"`
+
+	// KeepOpenedViews leaves the temporary files of opened views behind instead of removing them on exit
+	KeepOpenedViews bool `json:"keep_opened_views"`
+
+	// Daily JSON snapshots of the database (disabled when SnapshotDir is empty)
+	SnapshotDir           string `json:"snapshot_dir"`
+	SnapshotRetentionDays int    `json:"snapshot_retention_days"`
+
+	// Verbose logs go to awp_<date>.log in LogDir (/tmp when empty); older logs are removed
+	LogDir           string `json:"log_dir"`
+	LogRetentionDays int    `json:"log_retention_days"`
+
+	// TrashRetentionDays is how long deleted tasks stay in the trash before they are removed for good
+	// at startup (0 keeps them until --empty-trash)
+	TrashRetentionDays int `json:"trash_retention_days"`
+
+	// JumpToTodayPreservesFilter keeps the task filter and search when jumping to today
+	JumpToTodayPreservesFilter bool `json:"jump_to_today_preserves_filter"`
+
+	// Grouped view layout: header format ({name} and {count} placeholders) and
+	// separator between groups ("blank", "rule" or "none")
+	GroupHeaderFormat string `json:"group_header_format"`
+	GroupSeparator    string `json:"group_separator"`
+
+	// MaxGroups collapses the smallest groups into an "Other" group when there are more (0 for no limit)
+	MaxGroups int `json:"max_groups"`
+
+	// ResetViewIncludes lists what the reset view key clears: "filter", "search", "group", "focus"
+	// (the focused project) and "chips" (the project chip filter)
+	ResetViewIncludes []string `json:"reset_view_includes"`
+
+	// InheritViewFilterOnAdd tags new tasks with the +project/@context currently searched for
+	InheritViewFilterOnAdd bool `json:"inherit_view_filter_on_add"`
+
+	// WaitingContext names the context (without @) of delegated tasks: they are colored apart and
+	// not counted as pending or overdue (empty to disable)
+	WaitingContext string `json:"waiting_context"`
+
+	// ProjectDefaultContexts maps a project to contexts (both without prefix) that new tasks with the
+	// project get automatically, e.g. {"errands": ["out"]}
+	ProjectDefaultContexts map[string][]string `json:"project_default_contexts"`
+
+	// TagsCaseSensitive keeps tags like +work and +Work apart when removing duplicate tags from a task
+	TagsCaseSensitive bool `json:"tags_case_sensitive"`
+
+	// Templates are named task shapes that pre-fill the add form
+	Templates map[string]TaskTemplate `json:"templates"`
+
+	// LargeViewThreshold asks before switching to the all-tasks view when it holds more tasks (0 to never ask)
+	LargeViewThreshold int `json:"large_view_threshold"`
+
+	// MaxPinned limits how many tasks can be pinned to the top of their views (0 for no limit)
+	MaxPinned int `json:"max_pinned"`
+
+	// TableHeightAdjust adds rows to (or, when negative, takes rows from) the task table's height
+	// at the expense of the status lines below it
+	TableHeightAdjust int `json:"table_height_adjust"`
+
+	// CelebrateEmptyView shows a short note when the last open task of the view is completed or deleted
+	CelebrateEmptyView bool `json:"celebrate_empty_view"`
+
+	// ShowProgressBar shows a done/total bar below the task list
+	ShowProgressBar bool `json:"show_progress_bar"`
+
+	// ShowWeekSidebar starts with the next 7 days' pending task counts shown beside the task list
+	ShowWeekSidebar bool `json:"show_week_sidebar"`
+
+	// ReadOnly disables every change to the database (also set by --read-only)
+	ReadOnly bool `json:"read_only"`
+
+	// DeferOverdueTo is where the defer-overdue key moves overdue tasks: "today" or "tomorrow"
+	DeferOverdueTo string `json:"defer_overdue_to"`
+
+	// OverdueGraceDays is how many days past its due date a task may be before it counts as overdue
+	OverdueGraceDays int `json:"overdue_grace_days"`
+
+	// AlertOnOverdue rings the terminal bell and shows a banner at startup when tasks are overdue
+	AlertOnOverdue bool `json:"alert_on_overdue"`
+
+	// SymbolIndicators marks done (✓), overdue (!) and high priority (↑) tasks with symbols so they
+	// can be told apart without relying on color
+	SymbolIndicators bool `json:"symbol_indicators"`
+
+	// ColorDueDates colors the due column by how soon tasks are due
+	ColorDueDates bool `json:"color_due_dates"`
+
+	// StaleAfterDays marks undone tasks created more than this many days ago with their age (0 to disable)
+	StaleAfterDays int `json:"stale_after_days"`
+
+	// ShowCountdown adds how far off the due date is, like "3 days left", to undone tasks in
+	// the combined column
+	ShowCountdown bool `json:"show_countdown"`
+
+	// ShowDateInRows prefixes rows of the combined column with the due date as MM-DD: "on" outside
+	// the day view (where every row shares the date), "always" everywhere, "off" never
+	ShowDateInRows string `json:"show_date_in_rows"`
+
+	// Columns lists the task fields shown as table columns; empty shows one combined column
+	Columns []string `json:"columns"`
+
+	// MaxSearchResults caps how many tasks a search loads so very broad searches stay responsive
+	// (0 for no cap)
+	MaxSearchResults int `json:"max_search_results"`
+
+	// EmptyListOpensAdd makes the edit and delete keys open the add form when the view has no tasks
+	EmptyListOpensAdd bool `json:"empty_list_opens_add"`
+
+	// AdvanceAfterToggle moves the cursor to the next task after changing a task's status
+	AdvanceAfterToggle bool `json:"advance_after_toggle"`
+
+	// Timezone decides which calendar day it is (IANA name like "Europe/Berlin", empty for the system zone)
+	Timezone string `json:"timezone"`
+
+	// DayCutoffHour is the hour at which a new day starts; earlier hours still count as the previous day
+	DayCutoffHour int `json:"day_cutoff_hour"`
+
+	// SubmitOnEnter saves the add/edit form with enter from any field instead of moving to the next field
+	SubmitOnEnter bool `json:"submit_on_enter"`
+
+	// WarnDuplicates warns before adding an undone task with the same title and due date as an existing one
+	WarnDuplicates bool `json:"warn_duplicates"`
+
+	// WarnPastDueDate warns when adding a task due before today: "note" just mentions it,
+	// "confirm" asks to submit again (the CLI skips it unless --yes); empty disables the check
+	WarnPastDueDate string `json:"warn_past_due_date"`
+
+	// MaxTitleLength is the most characters a task title may have (0 for no limit); TitleOverflow
+	// decides what happens to longer titles: "reject" (the default) refuses them, "truncate" cuts them
+	MaxTitleLength int    `json:"max_title_length"`
+	TitleOverflow  string `json:"title_overflow"`
+
+	// CalendarFillHeight stretches the month calendar's week rows to fill the terminal height
+	CalendarFillHeight bool `json:"calendar_fill_height"`
+
+	// WeekStart is the first day of the week in the calendar and the week view: "sunday" or "monday"
+	WeekStart string `json:"week_start"`
+
+	// ShowWeekNumber adds the week number of the viewed day to the status line
+	ShowWeekNumber bool `json:"show_week_number"`
+
+	// SkipWeekends makes previous/next day navigation jump over the NonWorkingDays (weekday names)
+	SkipWeekends   bool     `json:"skip_weekends"`
+	NonWorkingDays []string `json:"non_working_days"`
+}
+
+// TaskTemplate describes a reusable task shape
+type TaskTemplate struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Projects    []string `json:"projects"`
+	Contexts    []string `json:"contexts"`
 }
 
 // Styles holds the application colors and styling information
@@ -31,10 +201,54 @@ type Styles struct {
 	// Project and context colors
 	ProjectColor string `json:"project_color"`
 	ContextColor string `json:"context_color"`
+
+	// Grouped view colors
+	GroupHeaderColor    string `json:"group_header_color"`
+	GroupSeparatorColor string `json:"group_separator_color"`
+
+	// Due date colors by proximity (used when color_due_dates is on)
+	DueOverdueColor  string `json:"due_overdue_color"`
+	DueTodayColor    string `json:"due_today_color"`
+	DueThisWeekColor string `json:"due_this_week_color"`
+
+	// Age suffix of stale tasks (used when stale_after_days is set)
+	StaleColor string `json:"stale_color"`
+
+	// Matches of a plain text search term in task rows
+	SearchHighlightColor string `json:"search_highlight_color"`
+
+	// The waiting_context tag
+	WaitingColor string `json:"waiting_color"`
+
+	// Due date prefix of rows (used when show_date_in_rows is set)
+	RowDateColor string `json:"row_date_color"`
+
+	// Marker of tasks not reviewed yet
+	UnreviewedColor string `json:"unreviewed_color"`
 }
 
-// Load loads the application configuration from the specified path
-func Load(configPath string) (Config, Styles, error) {
+// ColumnWidths holds the width of each table column that can be listed in Columns
+var ColumnWidths = map[string]int{
+	"status":      4,
+	"priority":    4,
+	"id":          5,
+	"due":         11,
+	"countdown":   18,
+	"created":     11,
+	"projects":    16,
+	"contexts":    16,
+	"title":       40,
+	"description": 40,
+}
+
+// ErrInvalidStyles marks a styles file that is not valid JSON or holds values of the wrong type.
+// Load returns the default styles with it, so the application can still start.
+var ErrInvalidStyles = errors.New("invalid styles file")
+
+// Load loads the application configuration from the specified path. With strict, keys that are
+// not known settings are reported as errors instead of being ignored. An error wrapping
+// ErrInvalidStyles comes with a usable config and the default styles.
+func Load(configPath string, strict bool) (Config, Styles, error) {
 	// Get user's home directory for storing the database
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -51,6 +265,43 @@ func Load(configPath string) (Config, Styles, error) {
 		Database:   defaultDbPath,
 		KeyMap:     keymaps.GetDefaultKeyMappings(),
 		StylesFile: filepath.Join(configDir, "styles.json"),
+
+		ShareTarget: "clipboard",
+
+		SnapshotRetentionDays: 30,
+		TrashRetentionDays:    30,
+
+		LogRetentionDays: 7,
+
+		MaxSearchResults: 500,
+
+		TitleOverflow: "reject",
+
+		JumpToTodayPreservesFilter: true,
+
+		GroupHeaderFormat: "== {name} ({count}) ==",
+		GroupSeparator:    "blank",
+
+		ResetViewIncludes: []string{"filter", "search", "group"},
+
+		Databases: map[string]string{},
+		Templates: map[string]TaskTemplate{},
+		Columns:   []string{},
+
+		NonWorkingDays: []string{"saturday", "sunday"},
+		WeekStart:      "sunday",
+
+		MaxPinned: 5,
+
+		DeferOverdueTo: "today",
+
+		OpenViewFormat: "md",
+
+		WaitingContext: "waiting",
+
+		CelebrateEmptyView: true,
+
+		ShowDateInRows: "off",
 	}
 
 	// If configPath is empty, use the default path
@@ -78,19 +329,25 @@ func Load(configPath string) (Config, Styles, error) {
 			if err := os.WriteFile(configPath, configData, 0644); err != nil {
 				return config, Styles{}, err
 			}
+			utils.Log("Created default config at %s", configPath)
 		} else {
 			// Some other error occurred while reading the file
 			return config, Styles{}, err
 		}
 	} else {
 		// File exists, parse it
-		if err := json.Unmarshal(configData, &config); err != nil {
+		if err := decodeJSON(configPath, configData, &config, strict); err != nil {
 			return config, Styles{}, err
 		}
+		utils.Log("Loaded config from %s", configPath)
+	}
+
+	if err := checkColumns(config.Columns); err != nil {
+		return config, Styles{}, fmt.Errorf("%s: %w", configPath, err)
 	}
 
 	// Now load the styles file
-	styles, err := loadStyles(config.StylesFile)
+	styles, err := loadStyles(config.StylesFile, strict)
 	if err != nil {
 		return config, styles, fmt.Errorf("error loading styles: %w", err)
 	}
@@ -99,7 +356,7 @@ func Load(configPath string) (Config, Styles, error) {
 }
 
 // loadStyles loads the application styles from the specified path
-func loadStyles(stylesPath string) (Styles, error) {
+func loadStyles(stylesPath string, strict bool) (Styles, error) {
 	// Default styles that match the current constants
 	defaultStyles := Styles{
 		BorderColor:       "240",
@@ -110,6 +367,23 @@ func loadStyles(stylesPath string) (Styles, error) {
 		ErrorColor:        "9",
 		ProjectColor:      "2",
 		ContextColor:      "4",
+
+		GroupHeaderColor:    "205",
+		GroupSeparatorColor: "240",
+
+		DueOverdueColor:  "196",
+		DueTodayColor:    "208",
+		DueThisWeekColor: "226",
+
+		StaleColor: "242",
+
+		SearchHighlightColor: "214",
+
+		WaitingColor: "141",
+
+		RowDateColor: "244",
+
+		UnreviewedColor: "244",
 	}
 
 	// Try to read the styles file
@@ -141,11 +415,96 @@ func loadStyles(stylesPath string) (Styles, error) {
 		}
 	}
 
-	// File exists, parse it
-	var loadedStyles Styles
-	if err := json.Unmarshal(stylesData, &loadedStyles); err != nil {
+	// File exists, parse it on top of the defaults so newly added colors are set
+	loadedStyles := defaultStyles
+	if err := decodeJSON(stylesPath, stylesData, &loadedStyles, strict); err != nil {
+		// A bad hand-edit should not keep the application from starting
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+			return defaultStyles, fmt.Errorf("%w %s: %v", ErrInvalidStyles, stylesPath, err)
+		}
 		return defaultStyles, err
 	}
 
 	return loadedStyles, nil
 }
+
+// checkColumns returns an error listing the column names that are not in ColumnWidths
+func checkColumns(columns []string) error {
+	var unknown []string
+	for _, column := range columns {
+		if _, ok := ColumnWidths[column]; !ok {
+			unknown = append(unknown, fmt.Sprintf("%q", column))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	known := make([]string, 0, len(ColumnWidths))
+	for column := range ColumnWidths {
+		known = append(known, column)
+	}
+	sort.Strings(known)
+	return fmt.Errorf("unknown columns %s (use %s)", strings.Join(unknown, ", "), strings.Join(known, ", "))
+}
+
+// decodeJSON parses data from path into v. With strict, unknown keys are rejected, listing every
+// unknown top-level key with its line number.
+func decodeJSON(path string, data []byte, v any, strict bool) error {
+	if !strict {
+		return json.Unmarshal(data, v)
+	}
+
+	unknown, err := unknownKeys(data, reflect.TypeOf(v).Elem())
+	if err != nil {
+		return err
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown keys in %s: %s", path, strings.Join(unknown, ", "))
+	}
+
+	// Catches unknown keys in nested objects such as templates
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// unknownKeys returns the top-level keys of the JSON object in data that match no json tag of the
+// struct type t, each as "key (line N)"
+func unknownKeys(data []byte, t reflect.Type) ([]string, error) {
+	known := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		known[name] = true
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil { // Opening brace
+		return nil, err
+	}
+
+	var unknown []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+		if !known[key] {
+			line := 1 + bytes.Count(data[:decoder.InputOffset()], []byte("\n"))
+			unknown = append(unknown, fmt.Sprintf("%q (line %d)", key, line))
+		}
+
+		// Skip the value
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return unknown, nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config using the given styles file and returns its path
+func writeConfig(t *testing.T, dir, stylesPath string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "config.json")
+	data := `{"database": "` + filepath.Join(dir, "todo.db") + `", "styles_file": "` + stylesPath + `", "max_pinned": 2}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMalformedStyles(t *testing.T) {
+	// A missing styles file is created with the defaults
+	dir := t.TempDir()
+	_, defaults, err := Load(writeConfig(t, dir, filepath.Join(dir, "styles.json")), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		styles string
+	}{
+		{"syntax error", `{"border_color": "1",`},
+		{"wrong type", `{"border_color": 1}`},
+		{"not an object", `["1"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			stylesPath := filepath.Join(dir, "styles.json")
+			if err := os.WriteFile(stylesPath, []byte(tt.styles), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, styles, err := Load(writeConfig(t, dir, stylesPath), false)
+			if !errors.Is(err, ErrInvalidStyles) {
+				t.Fatalf("Load error = %v, want ErrInvalidStyles", err)
+			}
+			if styles != defaults {
+				t.Errorf("styles = %+v, want the defaults", styles)
+			}
+			// The config itself is still loaded, so startup can go on
+			if cfg.MaxPinned != 2 || cfg.Database != filepath.Join(dir, "todo.db") {
+				t.Errorf("config not loaded: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns string
+		wantErr string
+	}{
+		{"none", `[]`, ""},
+		{"known", `["status", "due", "title", "projects"]`, ""},
+		{"misspelled", `["status", "tittle"]`, `unknown columns "tittle"`},
+		{"empty name", `[""]`, `unknown columns ""`},
+		{"several", `["Title", "tags"]`, `unknown columns "Title", "tags"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.json")
+			data := `{"styles_file": "` + filepath.Join(dir, "styles.json") + `", "columns": ` + tt.columns + `}`
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, err := Load(path, false)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Load: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error = %v, want it to contain %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// benchmarkTasks is the size of the seeded benchmark database
+const benchmarkTasks = 10000
+
+// newBenchmarkDB opens an in-memory database seeded with benchmarkTasks tasks spread over a year
+func newBenchmarkDB(b *testing.B) *sql.DB {
+	b.Helper()
+
+	db := newTestDB(b)
+	tx, err := db.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	stmt, err := tx.Prepare("INSERT INTO todos (status, state, title, description, duedate, projects, contexts, priority) VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < benchmarkTasks; i++ {
+		state := TaskState(i % 3)
+		title := fmt.Sprintf("task %d", (i*7919)%benchmarkTasks)
+		if _, err := stmt.Exec(state == StateDone, state, title, title+" +work", start.AddDate(0, 0, i%365), "work", "office", i%4); err != nil {
+			b.Fatal(err)
+		}
+	}
+	stmt.Close()
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+	return db
+}
+
+// BenchmarkSortByTitle compares sorting the undone tasks by title in SQL with loading them in the
+// default order and sorting in Go, as loadTasks does when tasks are grouped
+func BenchmarkSortByTitle(b *testing.B) {
+	db := newBenchmarkDB(b)
+	orderBy, _ := OrderByClause(SortByTitle, SortAsc)
+
+	b.Run("sql", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := LoadTasksSorted(db, "status = 0", orderBy); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("go", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tasks, err := LoadTasks(db, "status = 0")
+			if err != nil {
+				b.Fatal(err)
+			}
+			sort.Slice(tasks, func(i, j int) bool {
+				if cmp := strings.Compare(strings.ToLower(tasks[i].Title), strings.ToLower(tasks[j].Title)); cmp != 0 {
+					return cmp < 0
+				}
+				return tasks[i].ID < tasks[j].ID
+			})
+		}
+	})
+}
+
+// BenchmarkDayQueries runs the queries behind day navigation, the calendar and the status counts
+// with the indexes EnsureSchema creates and again after dropping them
+func BenchmarkDayQueries(b *testing.B) {
+	db := newBenchmarkDB(b)
+	from, to := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC)
+
+	queries := []struct {
+		name string
+		run  func() error
+	}{
+		{"day count", func() error {
+			// As findNextDayWithTasks checks each day
+			var count int
+			return db.QueryRow("SELECT COUNT(*) FROM todos WHERE deleted_at IS NULL AND date(duedate) = date(?)", "2026-10-17").Scan(&count)
+		}},
+		{"day tasks", func() error {
+			whereClause, args := BuildWhereClause(TodayViewMode, AllTasksFilter, "2026-10-17", "", "", "2026-10-17")
+			_, err := LoadTasks(db, whereClause, args...)
+			return err
+		}},
+		{"next day with tasks", func() error {
+			_, _, err := NearestTaskDate(db, "status = 0", "2026-10-18", true)
+			return err
+		}},
+		{"month counts", func() error {
+			_, err := CountTasksByDay(db, "status = 0", from, to)
+			return err
+		}},
+		{"undone count", func() error {
+			_, err := CountTasks(db, "status = 0")
+			return err
+		}},
+	}
+
+	benchmarkAll := func(b *testing.B) {
+		for _, query := range queries {
+			b.Run(query.name, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := query.run(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+
+	b.Run("indexed", benchmarkAll)
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'index' AND name LIKE 'idx_todos_%'")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var indexes []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			b.Fatal(err)
+		}
+		indexes = append(indexes, name)
+	}
+	rows.Close()
+	if len(indexes) == 0 {
+		b.Fatal("EnsureSchema created no indexes")
+	}
+	for _, name := range indexes {
+		if _, err := db.Exec("DROP INDEX " + name); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("unindexed", benchmarkAll)
+}
@@ -3,26 +3,31 @@ package database
 import (
 	"awp/pkg/utils"
 	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// ConnectDB establishes a connection to the SQLite database
-func ConnectDB(dbPath string) (*sql.DB, error) {
+// ConnectDB establishes a connection to the SQLite database. A read-only connection refuses every
+// write and needs an existing database.
+func ConnectDB(dbPath string, readOnly bool) (*sql.DB, error) {
 	// Expand tilde to home directory if present
-	if strings.HasPrefix(dbPath, "~") {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
-		}
-		dbPath = homeDir + dbPath[1:]
+	dbPath, err := utils.ExpandHome(dbPath)
+	if err != nil {
+		return nil, err
 	}
 
 	utils.Log("Connecting to database: %s", dbPath)
 
+	if readOnly {
+		if _, err := os.Stat(dbPath); err != nil {
+			return nil, err
+		}
+		return sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	}
+
 	// Create the directory structure if it doesn't exist
 	dbDir := filepath.Dir(dbPath)
 	if dbDir != "." {
@@ -36,21 +41,158 @@ func ConnectDB(dbPath string) (*sql.DB, error) {
 	return sql.Open("sqlite3", dbPath)
 }
 
-// EnsureSchema creates the database schema if it doesn't exist
-func EnsureSchema(db *sql.DB) error {
+// OpenMemory opens an empty in-memory database with the current schema, as used by tests
+func OpenMemory() (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// Every connection to :memory: is a separate database, so keep to one
+	db.SetMaxOpenConns(1)
+
+	if err := EnsureSchema(db, false); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migratedColumns are the columns added to the todos table after it was first created
+var migratedColumns = []string{"state", "priority", "pinned", "waiting_until", "deleted_at", "reviewed"}
+
+// EnsureSchema creates the database schema if it doesn't exist and migrates older databases. A
+// read-only database is only checked, since it can't be migrated.
+func EnsureSchema(db *sql.DB, readOnly bool) error {
+	if readOnly {
+		return checkSchema(db)
+	}
+
 	// Create todos table if it doesn't exist
 	_, err := db.Exec(`
 		CREATE TABLE IF NOT EXISTS todos (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			status BOOLEAN NOT NULL DEFAULT 0,
+			state INTEGER NOT NULL DEFAULT 0,
 			created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			lastmodified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			duedate TIMESTAMP,
 			title TEXT NOT NULL,
 			description TEXT,
 			projects TEXT,
-			contexts TEXT
+			contexts TEXT,
+			priority INTEGER NOT NULL DEFAULT 0,
+			pinned INTEGER NOT NULL DEFAULT 0,
+			reviewed INTEGER NOT NULL DEFAULT 0
 		)
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Databases created before the state column existed only have the boolean status
+	added, err := ensureColumn(db, "state", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	if added {
+		if _, err := db.Exec("UPDATE todos SET state = ? WHERE status = 1", StateDone); err != nil {
+			return err
+		}
+		utils.Log("Migrated status column to state")
+	}
+
+	if _, err := ensureColumn(db, "priority", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	if _, err := ensureColumn(db, "pinned", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	if _, err := ensureColumn(db, "waiting_until", "TIMESTAMP"); err != nil {
+		return err
+	}
+
+	if _, err := ensureColumn(db, "deleted_at", "TIMESTAMP"); err != nil {
+		return err
+	}
+
+	// Only tasks added from now on need a review
+	added, err = ensureColumn(db, "reviewed", "INTEGER NOT NULL DEFAULT 0")
+	if err != nil {
+		return err
+	}
+	if added {
+		if _, err := db.Exec("UPDATE todos SET reviewed = 1"); err != nil {
+			return err
+		}
+	}
+
+	// Day navigation and the calendar filter on date(duedate), so that expression gets its own index
+	for _, index := range []string{
+		"CREATE INDEX IF NOT EXISTS idx_todos_duedate ON todos (duedate)",
+		"CREATE INDEX IF NOT EXISTS idx_todos_due_day ON todos (date(duedate))",
+		"CREATE INDEX IF NOT EXISTS idx_todos_status ON todos (status)",
+	} {
+		if _, err := db.Exec(index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSchema returns an error if the todos table is missing or needs a migration
+func checkSchema(db *sql.DB) error {
+	columns, err := todoColumns(db)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("the database has no todos table")
+	}
+	for _, name := range migratedColumns {
+		if !columns[name] {
+			return fmt.Errorf("the database is missing the %s column; open it once without read-only mode to update it", name)
+		}
+	}
+	return nil
+}
+
+// todoColumns returns the names of the columns of the todos table (none if it doesn't exist)
+func todoColumns(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query("PRAGMA table_info(todos)")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var cid, notNull, pk int
+		var colName, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &colName, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[colName] = true
+	}
+	return columns, rows.Err()
+}
+
+// ensureColumn adds a column to the todos table if it is missing and reports whether it was added
+func ensureColumn(db *sql.DB, name, definition string) (bool, error) {
+	columns, err := todoColumns(db)
+	if err != nil {
+		return false, err
+	}
+	if columns[name] {
+		return false, nil
+	}
+
+	if _, err := db.Exec("ALTER TABLE todos ADD COLUMN " + name + " " + definition); err != nil {
+		return false, err
+	}
+	utils.Log("Added column %s to todos", name)
+	return true, nil
 }
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadOnlyConnection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo.db")
+
+	db, err := ConnectDB(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureSchema(db, false); err != nil {
+		t.Fatal(err)
+	}
+	addTestTask(t, db, TodoItem{Title: "existing", DueDate: date(t, "2026-10-17")})
+	db.Close()
+
+	db, err = ConnectDB(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := EnsureSchema(db, true); err != nil {
+		t.Fatalf("EnsureSchema on an up to date database: %v", err)
+	}
+
+	tasks, err := LoadTasks(db, "")
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("LoadTasks = %d tasks, %v; want 1", len(tasks), err)
+	}
+	if err := AddTask(db, TodoItem{Title: "new"}); err == nil {
+		t.Error("AddTask succeeded on a read-only connection")
+	}
+}
+
+func TestReadOnlySchemaIsNotMigrated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+
+	// A database from before the state column and everything added after it
+	db, err := ConnectDB(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE todos (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		status BOOLEAN NOT NULL DEFAULT 0,
+		created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		lastmodified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		duedate TIMESTAMP,
+		title TEXT NOT NULL,
+		description TEXT,
+		projects TEXT,
+		contexts TEXT
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = ConnectDB(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := EnsureSchema(db, true); err == nil {
+		t.Error("EnsureSchema accepted a database that needs a migration")
+	}
+
+	columns, err := todoColumns(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns["state"] || columns["reviewed"] {
+		t.Errorf("read-only EnsureSchema added columns: %v", columns)
+	}
+	var indexes int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name LIKE 'idx_todos_%'").Scan(&indexes); err != nil {
+		t.Fatal(err)
+	}
+	if indexes != 0 {
+		t.Errorf("read-only EnsureSchema created %d indexes", indexes)
+	}
+}
+
+func TestReadOnlyConnectionNeedsDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "todo.db")
+
+	if _, err := ConnectDB(path, true); err == nil {
+		t.Error("ConnectDB opened a missing database read-only")
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Error("ConnectDB created the directory of a read-only database")
+	}
+}
@@ -1,13 +1,15 @@
 package database
 
 import (
+	"strings"
 	"time"
 )
 
 // TodoItem represents a single todo task
 type TodoItem struct {
 	ID           int       `db:"id"`
-	Status       bool      `db:"status"`
+	Status       bool      `db:"status"` // True when State is StateDone
+	State        TaskState `db:"state"`
 	Title        string    `db:"title"`
 	Description  string    `db:"description"`
 	Created      time.Time `db:"created"`
@@ -15,6 +17,57 @@ type TodoItem struct {
 	DueDate      time.Time `db:"duedate"`
 	Projects     []string  `db:"projects"`
 	Contexts     []string  `db:"contexts"`
+	Priority     int       `db:"priority"`      // 0 (none) to MaxPriority
+	Pinned       bool      `db:"pinned"`        // Shown at the top of the views it belongs to
+	WaitingUntil time.Time `db:"waiting_until"` // Hidden until this follow-up date while undone; zero when not waiting
+	DeletedAt    time.Time `db:"deleted_at"`    // When the task was moved to the trash; zero when not deleted
+	Reviewed     bool      `db:"reviewed"`      // Seen during a review pass; new tasks start unreviewed
+}
+
+// MaxPriority is the highest task priority; 0 means no priority
+const MaxPriority = 3
+
+// IsWaiting reports whether the undone task is waiting on someone else and hidden as of now
+func (t TodoItem) IsWaiting(now time.Time) bool {
+	return !t.Status && !t.WaitingUntil.IsZero() && now.Format("2006-01-02") < t.WaitingUntil.Format("2006-01-02")
+}
+
+// NeedsFollowUp reports whether the undone task was waiting and its follow-up date has come
+func (t TodoItem) NeedsFollowUp(now time.Time) bool {
+	return !t.Status && !t.WaitingUntil.IsZero() && !t.IsWaiting(now)
+}
+
+// SetState updates the task state and keeps the Status flag in sync
+func (t *TodoItem) SetState(state TaskState) {
+	t.State = state
+	t.Status = state == StateDone
+}
+
+// IsOverdue reports whether an undone task is more than graceDays days past its due date as of now.
+// Tasks still waiting on someone else are not overdue until their follow-up date.
+func (t TodoItem) IsOverdue(now time.Time, graceDays int) bool {
+	if t.Status || t.DueDate.IsZero() || t.IsWaiting(now) {
+		return false
+	}
+
+	// Compare calendar days only
+	due := time.Date(t.DueDate.Year(), t.DueDate.Month(), t.DueDate.Day(), 0, 0, 0, 0, now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return today.After(due.AddDate(0, 0, graceDays))
+}
+
+// TaskState represents the workflow state of a task
+type TaskState int
+
+const (
+	StateTodo TaskState = iota
+	StateInProgress
+	StateDone
+)
+
+// Next returns the state that follows s when cycling through states
+func (s TaskState) Next() TaskState {
+	return (s + 1) % 3
 }
 
 // ViewMode represents the current view mode for tasks
@@ -24,15 +77,18 @@ const (
 	TodayViewMode ViewMode = iota // Default - show tasks for today
 	AllViewMode                   // Show all tasks (no date filter)
 	CalendarViewMode
+	WeekViewMode // Show the tasks of the week starting on the view date
 )
 
 // TaskFilter represents the current task filter mode
 type TaskFilter int
 
 const (
-	AllTasksFilter    TaskFilter = iota // Show all tasks regardless of status
-	DoneTasksFilter                     // Show only completed tasks
-	UndoneTasksFilter                   // Show only uncompleted tasks
+	AllTasksFilter        TaskFilter = iota // Show all tasks regardless of status
+	DoneTasksFilter                         // Show only completed tasks
+	UndoneTasksFilter                       // Show only uncompleted tasks
+	UntaggedTasksFilter                     // Show only tasks without projects and contexts
+	UnreviewedTasksFilter                   // Show only tasks not marked as reviewed
 )
 
 // SortBy represents different sorting options
@@ -46,6 +102,7 @@ const (
 	SortByContext
 	SortByCreated
 	SortByStatus
+	SortByPriority
 )
 
 // GroupBy represents different grouping options
@@ -59,6 +116,7 @@ const (
 	GroupByDueDateWeekly
 	GroupByDueDateMonthly
 	GroupByDueDateYearly
+	GroupByStatus
 )
 
 // SortOrder represents sorting direction
@@ -68,3 +126,22 @@ const (
 	SortAsc SortOrder = iota
 	SortDesc
 )
+
+// DedupeTags removes repeated projects or contexts, keeping the first spelling seen.
+// Unless caseSensitive is set, tags differing only in case count as the same tag.
+func DedupeTags(tags []string, caseSensitive bool) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, tag := range tags {
+		key := tag
+		if !caseSensitive {
+			key = strings.ToLower(tag)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDedupeTags(t *testing.T) {
+	tests := []struct {
+		name          string
+		tags          []string
+		caseSensitive bool
+		want          []string
+	}{
+		{"case insensitive", []string{"work", "home", "Work", "WORK"}, false, []string{"work", "home"}},
+		{"first spelling wins", []string{"Work", "work"}, false, []string{"Work"}},
+		{"case sensitive", []string{"work", "home", "Work", "work"}, true, []string{"work", "home", "Work"}},
+		{"sub-projects stay apart", []string{"work", "work/clientA", "Work/ClientA"}, false, []string{"work", "work/clientA"}},
+		{"no duplicates", []string{"b", "a", "c"}, false, []string{"b", "a", "c"}},
+		{"none", nil, false, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DedupeTags(tt.tags, tt.caseSensitive); !slices.Equal(got, tt.want) {
+				t.Errorf("DedupeTags(%q, %v) = %q, want %q", tt.tags, tt.caseSensitive, got, tt.want)
+			}
+		})
+	}
+}
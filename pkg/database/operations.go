@@ -4,21 +4,61 @@ import (
 	"awp/pkg/utils"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 )
 
-// LoadTasks retrieves tasks from the database based on the where clause
-func LoadTasks(db *sql.DB, whereClause string) ([]TodoItem, error) {
+// notDeletedClause matches tasks that are not in the trash
+const notDeletedClause = "deleted_at IS NULL"
+
+// withoutDeleted narrows a where clause (which may be empty) to tasks that are not in the trash.
+// Every query for tasks goes through it, so trashed tasks stay out of views, counts and exports.
+func withoutDeleted(whereClause string) string {
+	if whereClause == "" {
+		return notDeletedClause
+	}
+	return notDeletedClause + " AND (" + whereClause + ")"
+}
+
+// LoadTasks retrieves tasks from the database based on the where clause, newest due date first.
+// The TUI sorts these again in Go; use LoadTasksSorted with OrderByClause to have SQLite sort.
+// args fill the clause's ? placeholders, as for every function taking a where clause.
+func LoadTasks(db *sql.DB, whereClause string, args ...interface{}) ([]TodoItem, error) {
+	return LoadTasksSorted(db, whereClause, "duedate DESC, id DESC", args...)
+}
+
+// LoadTasksSorted retrieves tasks matching the where clause in the given ORDER BY order
+func LoadTasksSorted(db *sql.DB, whereClause string, orderBy string, args ...interface{}) ([]TodoItem, error) {
+	return queryTasks(db, withoutDeleted(whereClause), orderBy, 0, args...)
+}
+
+// LoadTasksLimited retrieves at most limit tasks matching the where clause in the given ORDER BY order
+func LoadTasksLimited(db *sql.DB, whereClause string, orderBy string, limit int, args ...interface{}) ([]TodoItem, error) {
+	return queryTasks(db, withoutDeleted(whereClause), orderBy, limit, args...)
+}
+
+// LoadTrash retrieves the tasks in the trash, most recently deleted first
+func LoadTrash(db *sql.DB) ([]TodoItem, error) {
+	return queryTasks(db, "deleted_at IS NOT NULL", "deleted_at DESC, id DESC", 0)
+}
+
+// queryTasks retrieves the tasks matching the where clause, trashed or not, in the given order;
+// a limit above 0 caps how many are returned
+func queryTasks(db *sql.DB, whereClause string, orderBy string, limit int, args ...interface{}) ([]TodoItem, error) {
 	query := `
-		SELECT id, status, title, description, created, lastmodified, duedate, projects, contexts
+		SELECT id, status, state, title, description, created, lastmodified, duedate, projects, contexts, priority, pinned, waiting_until, deleted_at, reviewed
 		FROM todos
 	`
 	if whereClause != "" {
 		query += " WHERE " + whereClause
 	}
-	query += " ORDER BY duedate DESC"
+	query += " ORDER BY " + orderBy
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
 
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -28,20 +68,26 @@ func LoadTasks(db *sql.DB, whereClause string) ([]TodoItem, error) {
 
 	for rows.Next() {
 		var item TodoItem
-		var dueDate sql.NullTime
-		var projectsStr string
-		var contextsStr string
+		var dueDate, waitingUntil, deletedAt sql.NullTime
+		var title, description sql.NullString
+		var projectsStr, contextsStr sql.NullString
 
 		if err := rows.Scan(
 			&item.ID,
 			&item.Status,
-			&item.Title,
-			&item.Description,
+			&item.State,
+			&title,
+			&description,
 			&item.Created,
 			&item.LastModified,
 			&dueDate,
 			&projectsStr,
 			&contextsStr,
+			&item.Priority,
+			&item.Pinned,
+			&waitingUntil,
+			&deletedAt,
+			&item.Reviewed,
 		); err != nil {
 			return nil, err
 		}
@@ -49,10 +95,23 @@ func LoadTasks(db *sql.DB, whereClause string) ([]TodoItem, error) {
 		if dueDate.Valid {
 			item.DueDate = dueDate.Time
 		}
+		if waitingUntil.Valid {
+			item.WaitingUntil = waitingUntil.Time
+		}
+		if deletedAt.Valid {
+			item.DeletedAt = deletedAt.Time
+		}
+
+		// Externally edited databases may hold NULLs in the text columns
+		item.Title = title.String
+		item.Description = description.String
+
+		// The state column is authoritative; status is derived from it
+		item.SetState(item.State)
 
 		// Parse projects from comma-separated string
-		if projectsStr != "" {
-			item.Projects = strings.Split(projectsStr, ",")
+		if projectsStr.String != "" {
+			item.Projects = strings.Split(projectsStr.String, ",")
 			for i, project := range item.Projects {
 				item.Projects[i] = strings.TrimSpace(project)
 			}
@@ -61,8 +120,8 @@ func LoadTasks(db *sql.DB, whereClause string) ([]TodoItem, error) {
 		}
 
 		// Parse contexts from comma-separated string
-		if contextsStr != "" {
-			item.Contexts = strings.Split(contextsStr, ",")
+		if contextsStr.String != "" {
+			item.Contexts = strings.Split(contextsStr.String, ",")
 			for i, context := range item.Contexts {
 				item.Contexts[i] = strings.TrimSpace(context)
 			}
@@ -78,17 +137,182 @@ func LoadTasks(db *sql.DB, whereClause string) ([]TodoItem, error) {
 	return items, nil
 }
 
+// OrderByClause maps a sort field and order to an SQL ORDER BY expression. It reports false for
+// fields that depend on parsed tags (project, context) and must be sorted in Go instead.
+func OrderByClause(sortBy SortBy, order SortOrder) (string, bool) {
+	var column string
+	descending := order == SortDesc
+
+	switch sortBy {
+	case SortByTitle:
+		column = "LOWER(title)"
+	case SortByDescription:
+		column = "LOWER(description)"
+	case SortByDueDate:
+		column = "duedate"
+	case SortByCreated:
+		column = "created"
+	case SortByStatus:
+		column = "state"
+	case SortByPriority:
+		// Ascending shows the highest priority first
+		column = "priority"
+		descending = !descending
+	default:
+		return "", false
+	}
+
+	direction := "ASC"
+	if descending {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s %s, id %s", column, direction, direction), true
+}
+
+// CountTasks returns the number of tasks matching the where clause
+func CountTasks(db *sql.DB, whereClause string, args ...interface{}) (int, error) {
+	query := "SELECT COUNT(*) FROM todos WHERE " + withoutDeleted(whereClause)
+
+	var count int
+	err := db.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
+
+// BuildDateRangeClause returns a parameterized clause matching tasks due from from to to (inclusive,
+// compared by day) and its arguments. Reversed bounds are swapped. It can be joined with other
+// clauses using AND.
+func BuildDateRangeClause(from, to time.Time) (string, []interface{}) {
+	if to.Before(from) {
+		from, to = to, from
+	}
+	return "date(duedate) BETWEEN date(?) AND date(?)", []interface{}{from.Format("2006-01-02"), to.Format("2006-01-02")}
+}
+
+// CountTasksByDay returns the number of tasks matching the where clause due on each day from from
+// to to (inclusive), keyed by date (YYYY-MM-DD); days without tasks are missing from the map
+func CountTasksByDay(db *sql.DB, whereClause string, from, to time.Time, args ...interface{}) (map[string]int, error) {
+	rangeClause, rangeArgs := BuildDateRangeClause(from, to)
+	query := "SELECT date(duedate), COUNT(*) FROM todos WHERE " + rangeClause
+	query += " AND " + withoutDeleted(whereClause)
+	query += " GROUP BY date(duedate)"
+
+	rows, err := db.Query(query, append(rangeArgs, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, err
+		}
+		counts[day] = count
+	}
+	return counts, rows.Err()
+}
+
+// DistinctProjects returns the sorted, distinct projects of the tasks matching the where clause
+func DistinctProjects(db *sql.DB, whereClause string, args ...interface{}) ([]string, error) {
+	query := "SELECT DISTINCT projects FROM todos WHERE projects IS NOT NULL AND projects != ''"
+	query += " AND " + withoutDeleted(whereClause)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	var projects []string
+	for rows.Next() {
+		var projectsStr string
+		if err := rows.Scan(&projectsStr); err != nil {
+			return nil, err
+		}
+		for _, project := range strings.Split(projectsStr, ",") {
+			project = strings.TrimSpace(project)
+			if project != "" && !seen[project] {
+				seen[project] = true
+				projects = append(projects, project)
+			}
+		}
+	}
+	sort.Strings(projects)
+	return projects, rows.Err()
+}
+
+// ProjectsClause matches tasks tagged with any of the projects and returns the clause's arguments
+func ProjectsClause(projects []string) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for _, project := range projects {
+		clauses = append(clauses, projectTagClause)
+		args = append(args, "%,"+escapeLike(project)+",%")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// projectTagClause matches tasks with a project (as a "%,name,%" pattern) among their projects
+const projectTagClause = `(',' || projects || ',') LIKE ? ESCAPE '\'`
+
+// escapeLike escapes the LIKE wildcards in text so it only matches itself; use it with ESCAPE '\'
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
+
+// NearestTaskDate returns the due date closest to from (YYYY-MM-DD) of a task matching the where
+// clause, looking on or after from when forward is true and on or before it otherwise
+func NearestTaskDate(db *sql.DB, whereClause string, from string, forward bool, args ...interface{}) (time.Time, bool, error) {
+	query := "SELECT MAX(date(duedate)) FROM todos WHERE date(duedate) <= date(?)"
+	if forward {
+		query = "SELECT MIN(date(duedate)) FROM todos WHERE date(duedate) >= date(?)"
+	}
+	query += " AND " + withoutDeleted(whereClause)
+
+	var dateStr sql.NullString
+	if err := db.QueryRow(query, append([]interface{}{from}, args...)...).Scan(&dateStr); err != nil {
+		return time.Time{}, false, err
+	}
+	if !dateStr.Valid {
+		return time.Time{}, false, nil
+	}
+
+	date, err := time.Parse("2006-01-02", dateStr.String)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return date, true, nil
+}
+
+// normalizedState returns the task state, reconciled with the Status flag for callers that only set Status
+func normalizedState(task TodoItem) TaskState {
+	if task.Status && task.State != StateDone {
+		return StateDone
+	}
+	if !task.Status && task.State == StateDone {
+		return StateTodo
+	}
+	return task.State
+}
+
 // AddTask inserts a new task into the database
 func AddTask(db *sql.DB, task TodoItem) error {
+	state := normalizedState(task)
 	res, err := db.Exec(
-		`INSERT INTO todos (status, title, description, created, lastmodified, duedate, projects, contexts) 
-		 VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, ?, ?, ?)`,
-		task.Status,
+		`INSERT INTO todos (status, state, title, description, created, lastmodified, duedate, projects, contexts, priority, pinned) 
+		 VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, ?, ?, ?, ?, ?)`,
+		state == StateDone,
+		state,
 		task.Title,
 		task.Description,
-		task.DueDate,
+		utils.DateOnly(task.DueDate),
 		strings.Join(task.Projects, ","),
 		strings.Join(task.Contexts, ","),
+		task.Priority,
+		task.Pinned,
 	)
 	if err != nil {
 		return err
@@ -101,15 +325,19 @@ func AddTask(db *sql.DB, task TodoItem) error {
 
 // UpdateTask updates an existing task in the database
 func UpdateTask(db *sql.DB, task TodoItem) error {
+	state := normalizedState(task)
 	_, err := db.Exec(
-		`UPDATE todos SET status = ?, title = ?, description = ?, lastmodified = CURRENT_TIMESTAMP, duedate = ?, projects = ?, contexts = ? 
+		`UPDATE todos SET status = ?, state = ?, title = ?, description = ?, lastmodified = CURRENT_TIMESTAMP, duedate = ?, projects = ?, contexts = ?, priority = ?, pinned = ? 
 		 WHERE id = ?`,
-		task.Status,
+		state == StateDone,
+		state,
 		task.Title,
 		task.Description,
-		task.DueDate,
+		utils.DateOnly(task.DueDate),
 		strings.Join(task.Projects, ","),
 		strings.Join(task.Contexts, ","),
+		task.Priority,
+		task.Pinned,
 		task.ID,
 	)
 	utils.Log("Updated task: %d", task.ID)
@@ -118,84 +346,305 @@ func UpdateTask(db *sql.DB, task TodoItem) error {
 
 // UpdateTaskStatus updates only the status of a task
 func UpdateTaskStatus(db *sql.DB, id int, status bool) error {
+	state := StateTodo
+	if status {
+		state = StateDone
+	}
+	return UpdateTaskState(db, id, state)
+}
+
+// UpdateTaskState updates only the state (and derived status) of a task
+func UpdateTaskState(db *sql.DB, id int, state TaskState) error {
 	_, err := db.Exec(
-		"UPDATE todos SET status = ?, lastmodified = CURRENT_TIMESTAMP WHERE id = ?",
-		status, id,
+		"UPDATE todos SET status = ?, state = ?, lastmodified = CURRENT_TIMESTAMP WHERE id = ?",
+		state == StateDone, state, id,
+	)
+	return err
+}
+
+// FindDuplicate returns an undone task with the given title due on the same day as date, or nil if there is none
+func FindDuplicate(db *sql.DB, title string, date time.Time) (*TodoItem, error) {
+	return findTask(db, "status = 0 AND title = ? AND date(duedate) = ?", title, date.Format("2006-01-02"))
+}
+
+// FindDuplicateTasks returns the tasks with the same title and due date as a task added before them
+// (and with matchProjects the same projects), oldest first. The earliest task of each set is kept.
+func FindDuplicateTasks(db *sql.DB, matchProjects bool) ([]TodoItem, error) {
+	condition := "o.title = todos.title AND date(o.duedate) IS date(todos.duedate)"
+	if matchProjects {
+		condition += " AND COALESCE(o.projects, '') = COALESCE(todos.projects, '')"
+	}
+	whereClause := fmt.Sprintf("EXISTS (SELECT 1 FROM todos o WHERE o.deleted_at IS NULL AND o.id < todos.id AND %s)", condition)
+	return LoadTasksSorted(db, whereClause, "id")
+}
+
+// FindTaskByTitleDate returns a task with the given title due on the same day as date, or nil if there is none
+func FindTaskByTitleDate(db *sql.DB, title string, date time.Time) (*TodoItem, error) {
+	return findTask(db, "title = ? AND date(duedate) = ?", title, date.Format("2006-01-02"))
+}
+
+// LoadTaskByID returns the task with the given ID, or nil if there is none
+func LoadTaskByID(db *sql.DB, id int) (*TodoItem, error) {
+	return findTask(db, "id = ?", id)
+}
+
+// FindTasksByTitleLike returns the undone tasks whose title contains text (case-insensitive)
+func FindTasksByTitleLike(db *sql.DB, text string) ([]TodoItem, error) {
+	return LoadTasks(db, `status = 0 AND title LIKE ? ESCAPE '\'`, "%"+escapeLike(text)+"%")
+}
+
+// findTask returns the first task matching the parameterized condition, or nil if there is none
+func findTask(db *sql.DB, condition string, args ...any) (*TodoItem, error) {
+	var id int
+	err := db.QueryRow("SELECT id FROM todos WHERE "+withoutDeleted(condition)+" ORDER BY id LIMIT 1", args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := LoadTasks(db, "id = ?", id)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// MoveTasksDue sets the due date of every task matching the where clause and returns how many moved
+func MoveTasksDue(db *sql.DB, whereClause string, dueDate time.Time, args ...interface{}) (int64, error) {
+	res, err := db.Exec(
+		"UPDATE todos SET duedate = ?, lastmodified = CURRENT_TIMESTAMP WHERE "+withoutDeleted(whereClause),
+		append([]interface{}{utils.DateOnly(dueDate)}, args...)...,
 	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// SwapDueDates exchanges the due dates of two tasks in one transaction
+func SwapDueDates(db *sql.DB, first, second TodoItem) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	update := "UPDATE todos SET duedate = ?, lastmodified = CURRENT_TIMESTAMP WHERE id = ?"
+	if _, err := tx.Exec(update, utils.DateOnly(second.DueDate), first.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(update, utils.DateOnly(first.DueDate), second.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateTaskPinned pins or unpins a task
+func UpdateTaskPinned(db *sql.DB, id int, pinned bool) error {
+	_, err := db.Exec("UPDATE todos SET pinned = ?, lastmodified = CURRENT_TIMESTAMP WHERE id = ?", pinned, id)
 	return err
 }
 
-// DeleteTask removes a task from the database
+// SetReviewed marks a task as reviewed or unreviewed
+func SetReviewed(db *sql.DB, id int, reviewed bool) error {
+	_, err := db.Exec("UPDATE todos SET reviewed = ?, lastmodified = CURRENT_TIMESTAMP WHERE id = ?", reviewed, id)
+	return err
+}
+
+// UpdateTaskWaiting marks a task as waiting until the follow-up date, or clears waiting for a zero date
+func UpdateTaskWaiting(db *sql.DB, id int, until time.Time) error {
+	var value any
+	if !until.IsZero() {
+		value = utils.DateOnly(until)
+	}
+	_, err := db.Exec("UPDATE todos SET waiting_until = ?, lastmodified = CURRENT_TIMESTAMP WHERE id = ?", value, id)
+	return err
+}
+
+// DeleteTask moves a task to the trash, from where it can be restored until the trash is emptied
 func DeleteTask(db *sql.DB, id int) error {
-	_, err := db.Exec("DELETE FROM todos WHERE id = ?", id)
+	_, err := db.Exec("UPDATE todos SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", id)
 	return err
 }
 
-// BuildWhereClause builds a SQL where clause based on view mode, task filter, and search term
-func BuildWhereClause(viewMode ViewMode, taskFilter TaskFilter, viewDate string, searchTerm string) string {
-	var whereClause string
+// DeleteTasks moves the tasks with the given IDs to the trash in one transaction
+func DeleteTasks(db *sql.DB, ids []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	// First, set up the viewMode and taskFilter parts of the where clause
+	for _, id := range ids {
+		if _, err := tx.Exec("UPDATE todos SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RestoreTask takes a task back out of the trash
+func RestoreTask(db *sql.DB, id int) error {
+	_, err := db.Exec("UPDATE todos SET deleted_at = NULL, lastmodified = CURRENT_TIMESTAMP WHERE id = ?", id)
+	return err
+}
+
+// EmptyTrash permanently removes the tasks deleted more than olderThanDays days ago (all trashed
+// tasks for 0) and returns how many were removed
+func EmptyTrash(db *sql.DB, olderThanDays int) (int64, error) {
+	query := "DELETE FROM todos WHERE deleted_at IS NOT NULL"
+	if olderThanDays > 0 {
+		query += fmt.Sprintf(" AND deleted_at < datetime('now', '-%d days')", olderThanDays)
+	}
+
+	res, err := db.Exec(query)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// OverdueClause builds a SQL condition matching undone tasks that are more than graceDays days
+// past their due date as of today (YYYY-MM-DD), and its arguments. It mirrors TodoItem.IsOverdue.
+// Tasks without a due date are stored with the zero time, which is never overdue, and tasks still
+// waiting on someone else are left out like in the task list.
+func OverdueClause(today string, graceDays int) (string, []interface{}) {
+	return "status = 0 AND duedate IS NOT NULL AND date(duedate) > '0001-01-01' AND date(duedate) < date(?, ?)" +
+			" AND (waiting_until IS NULL OR date(waiting_until) <= date(?))",
+		[]interface{}{today, fmt.Sprintf("-%d days", graceDays), today}
+}
+
+// untaggedClause matches tasks without any project or context
+const untaggedClause = "COALESCE(projects, '') = '' AND COALESCE(contexts, '') = ''"
+
+// unreviewedClause matches tasks not marked as reviewed
+const unreviewedClause = "reviewed = 0"
+
+// statusQualifiers map the search qualifiers that scope a single search by status to their condition
+var statusQualifiers = map[string]string{
+	"done:": "status = 1",
+	"todo:": "status = 0",
+}
+
+// ParseStatusQualifier splits a leading status qualifier ("done:" or "todo:", e.g. "done: report")
+// off a search term. It returns the qualifier's condition ("" without one) and the rest of the term.
+func ParseStatusQualifier(searchTerm string) (string, string) {
+	term := strings.TrimSpace(searchTerm)
+	for qualifier, clause := range statusQualifiers {
+		if len(term) >= len(qualifier) && strings.EqualFold(term[:len(qualifier)], qualifier) {
+			return clause, strings.TrimSpace(term[len(qualifier):])
+		}
+	}
+	return "", searchTerm
+}
+
+// filterClause returns the condition of a task filter, or "" for all tasks
+func filterClause(taskFilter TaskFilter) string {
+	switch taskFilter {
+	case DoneTasksFilter:
+		return "status = 1" // SQLite uses 1 for true
+	case UndoneTasksFilter:
+		return "status = 0" // SQLite uses 0 for false
+	case UntaggedTasksFilter:
+		return untaggedClause
+	case UnreviewedTasksFilter:
+		return unreviewedClause
+	}
+	return ""
+}
+
+// BuildWhereClause builds a SQL where clause based on view mode, task filter, search term and
+// focused project (exact match, ignored when empty), and the arguments for its ? placeholders.
+// A status qualifier in the search term takes the place of a done/undone task filter. Waiting
+// tasks stay hidden until their follow-up date is reached as of today (YYYY-MM-DD).
+func BuildWhereClause(viewMode ViewMode, taskFilter TaskFilter, viewDate string, searchTerm string, focusProject string, today string) (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	add := func(clause string, clauseArgs ...interface{}) {
+		if clause != "" {
+			clauses = append(clauses, clause)
+			args = append(args, clauseArgs...)
+		}
+	}
+
+	statusClause, searchTerm := ParseStatusQualifier(searchTerm)
+	if statusClause != "" && (taskFilter == DoneTasksFilter || taskFilter == UndoneTasksFilter) {
+		taskFilter = AllTasksFilter
+	}
+
+	// First, scope the view mode to its dates
 	switch viewMode {
 	case AllViewMode:
-		// In AllViewMode, initially no date filter
-		whereClause = ""
-
-		// Then, handle task filters within AllViewMode
-		switch taskFilter {
-		case AllTasksFilter:
-			// No additional filter needed for all tasks
-		case DoneTasksFilter:
-			whereClause = "status = 1" // SQLite uses 1 for true
-		case UndoneTasksFilter:
-			whereClause = "status = 0" // SQLite uses 0 for false
-		}
+		// No date filter
 
 	case TodayViewMode:
 		// Show tasks for specific date
-		whereClause = fmt.Sprintf("date(duedate) = date('%s')", viewDate)
-
-		// Then, handle task filters within TodayViewMode
-		switch taskFilter {
-		case AllTasksFilter:
-			// No additional filter needed for all tasks
-		case DoneTasksFilter:
-			whereClause = whereClause + " AND status = 1"
-		case UndoneTasksFilter:
-			whereClause = whereClause + " AND status = 0"
+		add("date(duedate) = date(?)", viewDate)
+
+	case CalendarViewMode, WeekViewMode:
+		day, err := time.Parse("2006-01-02", viewDate)
+		if err != nil {
+			// Like an invalid date in the day view, this matches nothing
+			add("date(duedate) = date(?)", viewDate)
+			break
+		}
+
+		// Show tasks for the seven days starting on the view date, or in the calendar for the
+		// whole month containing it
+		from, to := day, day.AddDate(0, 0, 6)
+		if viewMode == CalendarViewMode {
+			from = day.AddDate(0, 0, 1-day.Day())
+			to = from.AddDate(0, 1, -1)
 		}
+		rangeClause, rangeArgs := BuildDateRangeClause(from, to)
+		add(rangeClause, rangeArgs...)
+
+	default:
+		// Unknown view modes don't restrict by date or status
+		taskFilter = AllTasksFilter
 	}
 
-	// Finally, add search term filter if one is set
+	// Then, handle the task filter
+	add(filterClause(taskFilter))
+
+	// Next, add search term filter if one is set
 	if searchTerm != "" {
-		var searchClause string
+		pattern := "%" + escapeLike(searchTerm) + "%"
 
 		// Check if searching for project with +project syntax
 		if strings.HasPrefix(searchTerm, "+") && len(searchTerm) > 1 {
-			projectName := searchTerm[1:] // Remove the + prefix
-			// Search in projects column or in description
-			searchClause = fmt.Sprintf("(projects LIKE '%%%s%%' OR description LIKE '%%%s%%')",
-				projectName, searchTerm)
+			projectName := escapeLike(searchTerm[1:]) // Remove the + prefix
+			// Search in projects column or in description, matching the project and its sub-projects
+			// (+work matches +work/clientA but not +workshop)
+			add(`((',' || projects || ',') LIKE ? ESCAPE '\' OR (',' || projects || ',') LIKE ? ESCAPE '\'`+
+				` OR (' ' || description || ' ') LIKE ? ESCAPE '\' OR (' ' || description || ' ') LIKE ? ESCAPE '\')`,
+				"%,"+projectName+",%", "%,"+projectName+"/%", "% +"+projectName+" %", "% +"+projectName+"/%")
 		} else if strings.HasPrefix(searchTerm, "@") && len(searchTerm) > 1 {
 			// Check if searching for context with @context syntax
-			contextName := searchTerm[1:] // Remove the @ prefix
+			contextName := escapeLike(searchTerm[1:]) // Remove the @ prefix
 			// Search in contexts column or in description
-			searchClause = fmt.Sprintf("(contexts LIKE '%%%s%%' OR description LIKE '%%%s%%')",
-				contextName, searchTerm)
+			add(`(contexts LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`, "%"+contextName+"%", pattern)
 		} else {
 			// Regular search in title or description
-			searchClause = fmt.Sprintf("(title LIKE '%%%s%%' OR description LIKE '%%%s%%')",
-				searchTerm, searchTerm)
+			add(`(title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`, pattern, pattern)
 		}
+	}
 
-		if whereClause == "" {
-			whereClause = searchClause
-		} else {
-			whereClause = whereClause + " AND " + searchClause
-		}
+	// Scope this search by status
+	add(statusClause)
+
+	// Hide undone tasks waiting on someone else until their follow-up date
+	add("(status = 1 OR waiting_until IS NULL OR date(waiting_until) <= date(?))", today)
+
+	// Pin the view to a single project
+	if focusProject != "" {
+		add(projectTagClause, "%,"+escapeLike(focusProject)+",%")
 	}
 
-	utils.Log("Built where clause: %s", whereClause)
+	whereClause := strings.Join(clauses, " AND ")
+	utils.Log("Built where clause: %s %v", whereClause, args)
 
-	return whereClause
+	return whereClause, args
 }
@@ -0,0 +1,482 @@
+package database
+
+import (
+	"database/sql"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"awp/pkg/utils"
+)
+
+// newTestDB opens an empty in-memory database with the current schema
+func newTestDB(t testing.TB) *sql.DB {
+	t.Helper()
+
+	db, err := OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// date parses a YYYY-MM-DD date for test data
+func date(t testing.TB, s string) time.Time {
+	t.Helper()
+
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// addTestTask adds a task and returns its ID
+func addTestTask(t testing.TB, db *sql.DB, task TodoItem) int {
+	t.Helper()
+
+	if err := AddTask(db, task); err != nil {
+		t.Fatal(err)
+	}
+	var id int
+	if err := db.QueryRow("SELECT MAX(id) FROM todos").Scan(&id); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// titles returns the titles of tasks, sorted
+func titles(tasks []TodoItem) []string {
+	result := []string{}
+	for _, task := range tasks {
+		result = append(result, task.Title)
+	}
+	slices.Sort(result)
+	return result
+}
+
+// seedWhereClauseTasks adds the tasks the BuildWhereClause cases select from
+func seedWhereClauseTasks(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	milk := addTestTask(t, db, TodoItem{Title: "buy milk", DueDate: date(t, "2026-10-17"), Projects: []string{"home"}, Contexts: []string{"errands"}})
+	addTestTask(t, db, TodoItem{Title: "write report", Status: true, DueDate: date(t, "2026-10-17"), Projects: []string{"work/clientA"}})
+	addTestTask(t, db, TodoItem{Title: "workshop prep", DueDate: date(t, "2026-10-20"), Projects: []string{"workshop"}})
+	addTestTask(t, db, TodoItem{Title: "it's done", DueDate: date(t, "2026-10-05")})
+	effort := addTestTask(t, db, TodoItem{Title: "100% effort", DueDate: date(t, "2026-11-02"), Projects: []string{"work"}})
+	waiting := addTestTask(t, db, TodoItem{Title: "waiting task", DueDate: date(t, "2026-10-17"), Contexts: []string{"errands"}})
+
+	for _, id := range []int{milk, effort} {
+		if err := SetReviewed(db, id, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := UpdateTaskWaiting(db, waiting, date(t, "2099-01-01")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildWhereClause(t *testing.T) {
+	db := newTestDB(t)
+	seedWhereClauseTasks(t, db)
+
+	const day = "2026-10-17"
+	tests := []struct {
+		name         string
+		viewMode     ViewMode
+		taskFilter   TaskFilter
+		viewDate     string
+		searchTerm   string
+		focusProject string
+		want         []string
+	}{
+		{"all", AllViewMode, AllTasksFilter, "", "", "", []string{"100% effort", "buy milk", "it's done", "workshop prep", "write report"}},
+		{"all done", AllViewMode, DoneTasksFilter, "", "", "", []string{"write report"}},
+		{"all undone", AllViewMode, UndoneTasksFilter, "", "", "", []string{"100% effort", "buy milk", "it's done", "workshop prep"}},
+		{"all untagged", AllViewMode, UntaggedTasksFilter, "", "", "", []string{"it's done"}},
+		{"all unreviewed", AllViewMode, UnreviewedTasksFilter, "", "", "", []string{"it's done", "workshop prep", "write report"}},
+
+		{"day", TodayViewMode, AllTasksFilter, day, "", "", []string{"buy milk", "write report"}},
+		{"day done", TodayViewMode, DoneTasksFilter, day, "", "", []string{"write report"}},
+		{"day undone", TodayViewMode, UndoneTasksFilter, day, "", "", []string{"buy milk"}},
+		{"day untagged", TodayViewMode, UntaggedTasksFilter, day, "", "", []string{}},
+		{"day unreviewed", TodayViewMode, UnreviewedTasksFilter, day, "", "", []string{"write report"}},
+
+		{"calendar month", CalendarViewMode, AllTasksFilter, day, "", "", []string{"buy milk", "it's done", "workshop prep", "write report"}},
+		{"calendar from the first", CalendarViewMode, AllTasksFilter, "2026-10-01", "", "", []string{"buy milk", "it's done", "workshop prep", "write report"}},
+		{"calendar done", CalendarViewMode, DoneTasksFilter, day, "", "", []string{"write report"}},
+		{"calendar undone", CalendarViewMode, UndoneTasksFilter, day, "", "", []string{"buy milk", "it's done", "workshop prep"}},
+		{"calendar untagged", CalendarViewMode, UntaggedTasksFilter, day, "", "", []string{"it's done"}},
+		{"calendar unreviewed", CalendarViewMode, UnreviewedTasksFilter, day, "", "", []string{"it's done", "workshop prep", "write report"}},
+		{"calendar next month", CalendarViewMode, AllTasksFilter, "2026-11-30", "", "", []string{"100% effort"}},
+
+		{"week", WeekViewMode, AllTasksFilter, day, "", "", []string{"buy milk", "workshop prep", "write report"}},
+		{"week done", WeekViewMode, DoneTasksFilter, day, "", "", []string{"write report"}},
+		{"week undone", WeekViewMode, UndoneTasksFilter, day, "", "", []string{"buy milk", "workshop prep"}},
+		{"week untagged", WeekViewMode, UntaggedTasksFilter, day, "", "", []string{}},
+		{"week unreviewed", WeekViewMode, UnreviewedTasksFilter, day, "", "", []string{"workshop prep", "write report"}},
+		{"week ends on the sixth day", WeekViewMode, AllTasksFilter, "2026-10-14", "", "", []string{"buy milk", "workshop prep", "write report"}},
+
+		{"unknown view mode ignores the filter", ViewMode(99), DoneTasksFilter, day, "", "", []string{"100% effort", "buy milk", "it's done", "workshop prep", "write report"}},
+
+		{"search title", AllViewMode, AllTasksFilter, "", "milk", "", []string{"buy milk"}},
+		{"search is case-insensitive", AllViewMode, AllTasksFilter, "", "MILK", "", []string{"buy milk"}},
+		{"search within day", TodayViewMode, AllTasksFilter, day, "report", "", []string{"write report"}},
+		{"search with filter", AllViewMode, UndoneTasksFilter, "", "report", "", []string{}},
+		{"search project", AllViewMode, AllTasksFilter, "", "+work", "", []string{"100% effort", "write report"}},
+		{"search context", AllViewMode, AllTasksFilter, "", "@errands", "", []string{"buy milk"}},
+		{"search done qualifier", AllViewMode, AllTasksFilter, "", "done: report", "", []string{"write report"}},
+		{"search todo qualifier", AllViewMode, AllTasksFilter, "", "todo: work", "", []string{"workshop prep"}},
+		{"qualifier replaces the status filter", AllViewMode, UndoneTasksFilter, "", "done: report", "", []string{"write report"}},
+		{"qualifier keeps other filters", AllViewMode, UntaggedTasksFilter, "", "todo:", "", []string{"it's done"}},
+		{"qualifier is case-insensitive", AllViewMode, AllTasksFilter, "", "DONE:", "", []string{"write report"}},
+
+		{"quote in search", AllViewMode, AllTasksFilter, "", "it's", "", []string{"it's done"}},
+		{"quote in project search", AllViewMode, AllTasksFilter, "", "+it's", "", []string{}},
+		{"quote in context search", AllViewMode, AllTasksFilter, "", "@it's", "", []string{}},
+		{"injection in search", AllViewMode, AllTasksFilter, "", "' OR 1=1; DROP TABLE todos; --", "", []string{}},
+		{"percent is literal", AllViewMode, AllTasksFilter, "", "%", "", []string{"100% effort"}},
+		{"underscore is literal", AllViewMode, AllTasksFilter, "", "_", "", []string{}},
+		{"quote in view date", TodayViewMode, AllTasksFilter, "2026-10-17' OR '1'='1", "", "", []string{}},
+
+		{"focus project", AllViewMode, AllTasksFilter, "", "", "work", []string{"100% effort"}},
+		{"focus project with search", AllViewMode, AllTasksFilter, "", "effort", "work", []string{"100% effort"}},
+		{"quote in focus project", AllViewMode, AllTasksFilter, "", "", "it's", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			whereClause, args := BuildWhereClause(tt.viewMode, tt.taskFilter, tt.viewDate, tt.searchTerm, tt.focusProject, day)
+			tasks, err := LoadTasks(db, whereClause, args...)
+			if err != nil {
+				t.Fatalf("LoadTasks(%q, %v): %v", whereClause, args, err)
+			}
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q\nclause: %s %v", got, tt.want, whereClause, args)
+			}
+		})
+	}
+
+	// The injection attempt must not have touched the table
+	if count, err := CountTasks(db, ""); err != nil || count != 6 {
+		t.Errorf("CountTasks after injection attempt = %d, %v; want 6", count, err)
+	}
+}
+
+func TestBuildWhereClausePlaceholders(t *testing.T) {
+	whereClause, args := BuildWhereClause(TodayViewMode, DoneTasksFilter, "2026-10-17", "it's", "home", "2026-10-17")
+
+	if got := strings.Count(whereClause, "?"); got != len(args) {
+		t.Errorf("%d placeholders but %d args in %q", got, len(args), whereClause)
+	}
+	for _, value := range []string{"2026-10-17", "it's", "home"} {
+		if strings.Contains(whereClause, value) {
+			t.Errorf("%q is part of the clause %q instead of its args", value, whereClause)
+		}
+	}
+}
+
+func TestBuildDateRangeClause(t *testing.T) {
+	db := newTestDB(t)
+	for _, day := range []string{"2026-09-30", "2026-10-01", "2026-10-17", "2026-10-31", "2026-11-01"} {
+		addTestTask(t, db, TodoItem{Title: day, DueDate: date(t, day)})
+	}
+
+	late := time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		wantArgs []interface{}
+		want     []string
+	}{
+		{"month", date(t, "2026-10-01"), date(t, "2026-10-31"), []interface{}{"2026-10-01", "2026-10-31"}, []string{"2026-10-01", "2026-10-17", "2026-10-31"}},
+		{"equal bounds", date(t, "2026-10-17"), date(t, "2026-10-17"), []interface{}{"2026-10-17", "2026-10-17"}, []string{"2026-10-17"}},
+		{"equal days at different times", late, date(t, "2026-10-17"), []interface{}{"2026-10-17", "2026-10-17"}, []string{"2026-10-17"}},
+		{"reversed bounds", date(t, "2026-10-31"), date(t, "2026-10-01"), []interface{}{"2026-10-01", "2026-10-31"}, []string{"2026-10-01", "2026-10-17", "2026-10-31"}},
+		{"across months", date(t, "2026-09-30"), date(t, "2026-10-01"), []interface{}{"2026-09-30", "2026-10-01"}, []string{"2026-09-30", "2026-10-01"}},
+		{"no tasks", date(t, "2027-01-01"), date(t, "2027-12-31"), []interface{}{"2027-01-01", "2027-12-31"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, args := BuildDateRangeClause(tt.from, tt.to)
+			if clause != "date(duedate) BETWEEN date(?) AND date(?)" {
+				t.Errorf("clause = %q", clause)
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+
+			// The clause composes with other conditions
+			tasks, err := LoadTasks(db, clause+" AND status = 0", args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountTasksByDay(t *testing.T) {
+	db := newTestDB(t)
+	addTestTask(t, db, TodoItem{Title: "a", DueDate: date(t, "2026-10-17")})
+	addTestTask(t, db, TodoItem{Title: "b", DueDate: date(t, "2026-10-17")})
+	addTestTask(t, db, TodoItem{Title: "c", DueDate: date(t, "2026-10-18"), Status: true})
+	addTestTask(t, db, TodoItem{Title: "d", DueDate: date(t, "2026-10-25")})
+
+	counts, err := CountTasksByDay(db, "status = ?", date(t, "2026-10-17"), date(t, "2026-10-23"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts["2026-10-17"] != 2 {
+		t.Errorf("counts = %v, want map[2026-10-17:2]", counts)
+	}
+}
+
+func TestBuildWhereClauseWaiting(t *testing.T) {
+	db := newTestDB(t)
+	id := addTestTask(t, db, TodoItem{Title: "waiting", DueDate: date(t, "2026-10-10")})
+	if err := UpdateTaskWaiting(db, id, date(t, "2026-10-18")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		today string
+		want  int
+	}{
+		{"2026-10-17", 0},
+		{"2026-10-18", 1},
+		{"2026-10-19", 1},
+	} {
+		whereClause, args := BuildWhereClause(AllViewMode, AllTasksFilter, "", "", "", tt.today)
+		count, err := CountTasks(db, whereClause, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != tt.want {
+			t.Errorf("today %s: %d tasks shown, want %d", tt.today, count, tt.want)
+		}
+	}
+}
+
+func TestProjectSearchHierarchy(t *testing.T) {
+	db := newTestDB(t)
+	day := date(t, "2026-10-17")
+	addTestTask(t, db, TodoItem{Title: "work", DueDate: day, Projects: []string{"work"}})
+	addTestTask(t, db, TodoItem{Title: "client", DueDate: day, Projects: []string{"home", "work/clientA"}})
+	addTestTask(t, db, TodoItem{Title: "nested", DueDate: day, Projects: []string{"work/clientA/billing"}})
+	addTestTask(t, db, TodoItem{Title: "workshop", DueDate: day, Projects: []string{"workshop"}, Description: "plan +workshop"})
+	addTestTask(t, db, TodoItem{Title: "homework", DueDate: day, Projects: []string{"homework"}})
+	addTestTask(t, db, TodoItem{Title: "untagged", DueDate: day, Description: "call about +work today"})
+	addTestTask(t, db, TodoItem{Title: "untagged client", DueDate: day, Description: "+work/clientB"})
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"+work", []string{"client", "nested", "untagged", "untagged client", "work"}},
+		{"+work/clientA", []string{"client", "nested"}},
+		{"+work/clientA/billing", []string{"nested"}},
+		{"+workshop", []string{"workshop"}},
+		{"+work/client", []string{}},
+		{"+home", []string{"client"}},
+		{"+wor", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			whereClause, args := BuildWhereClause(AllViewMode, AllTasksFilter, "", tt.search, "", "2026-10-17")
+			tasks, err := LoadTasks(db, whereClause, args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(tasks); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverdue(t *testing.T) {
+	db := newTestDB(t)
+	for _, day := range []string{"2026-10-10", "2026-10-14", "2026-10-15", "2026-10-16", "2026-10-17", "2026-10-18"} {
+		addTestTask(t, db, TodoItem{Title: day, DueDate: date(t, day)})
+	}
+	addTestTask(t, db, TodoItem{Title: "done", DueDate: date(t, "2026-10-10"), Status: true})
+	addTestTask(t, db, TodoItem{Title: "no date"})
+	waiting := addTestTask(t, db, TodoItem{Title: "waiting", DueDate: date(t, "2026-10-10")})
+	if err := UpdateTaskWaiting(db, waiting, date(t, "2026-10-20")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Late in the day, so only calendar days may count
+	now := time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC)
+	tests := []struct {
+		graceDays int
+		want      []string
+	}{
+		{0, []string{"2026-10-10", "2026-10-14", "2026-10-15", "2026-10-16"}},
+		{1, []string{"2026-10-10", "2026-10-14", "2026-10-15"}},
+		{2, []string{"2026-10-10", "2026-10-14"}},
+		{3, []string{"2026-10-10"}},
+		{7, []string{}},
+	}
+
+	all, err := LoadTasks(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		clause, args := OverdueClause(now.Format("2006-01-02"), tt.graceDays)
+		tasks, err := LoadTasks(db, clause, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := titles(tasks); !slices.Equal(got, tt.want) {
+			t.Errorf("OverdueClause grace %d: got %q, want %q", tt.graceDays, got, tt.want)
+		}
+
+		// IsOverdue must agree with the SQL condition on every task
+		for _, task := range all {
+			if want := slices.Contains(tt.want, task.Title); task.IsOverdue(now, tt.graceDays) != want {
+				t.Errorf("IsOverdue grace %d on %q = %v, want %v", tt.graceDays, task.Title, !want, want)
+			}
+		}
+	}
+}
+
+func TestLoadTasksNullColumns(t *testing.T) {
+	db := newTestDB(t)
+
+	// As an external tool might write it, leaving the optional columns NULL
+	if _, err := db.Exec("INSERT INTO todos (title, description, duedate, projects, contexts) VALUES ('external', NULL, NULL, NULL, NULL)"); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := LoadTasks(db, "")
+	if err != nil {
+		t.Fatalf("LoadTasks: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("LoadTasks returned %d tasks, want 1", len(tasks))
+	}
+	task := tasks[0]
+	if task.Title != "external" || task.Description != "" || !task.DueDate.IsZero() {
+		t.Errorf("task = %+v", task)
+	}
+	if len(task.Projects) != 0 || len(task.Contexts) != 0 {
+		t.Errorf("tags = %q %q, want none", task.Projects, task.Contexts)
+	}
+}
+
+func TestDueDateNearMidnight(t *testing.T) {
+	prevNow, prevLocation := utils.Now, utils.Location()
+	t.Cleanup(func() {
+		utils.Now = prevNow
+		utils.SetLocation(prevLocation)
+	})
+
+	tests := []struct {
+		name string
+		now  time.Time
+		zone *time.Location
+		want string
+	}{
+		{"behind UTC, after midnight UTC", time.Date(2026, 10, 18, 0, 30, 0, 0, time.UTC), time.FixedZone("UTC-7", -7*3600), "2026-10-17"},
+		{"behind UTC, before midnight UTC", time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC), time.FixedZone("UTC-7", -7*3600), "2026-10-17"},
+		{"ahead of UTC, before midnight UTC", time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC), time.FixedZone("UTC+9", 9*3600), "2026-10-18"},
+		{"ahead of UTC, after midnight UTC", time.Date(2026, 10, 18, 0, 30, 0, 0, time.UTC), time.FixedZone("UTC+9", 9*3600), "2026-10-18"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			utils.Now = func() time.Time { return tt.now }
+			utils.SetLocation(tt.zone)
+
+			// Added for "today" as the app sees it, then shown in that day's view
+			today := utils.Today(0)
+			addTestTask(t, db, TodoItem{Title: "near midnight", DueDate: today})
+
+			whereClause, args := BuildWhereClause(TodayViewMode, AllTasksFilter, tt.want, "", "", tt.want)
+			tasks, err := LoadTasks(db, whereClause, args...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != 1 {
+				t.Fatalf("view of %s shows %d tasks, want 1", tt.want, len(tasks))
+			}
+			if got := tasks[0].DueDate.Format("2006-01-02"); got != tt.want {
+				t.Errorf("due date = %s, want %s", got, tt.want)
+			}
+			if got := utils.CountdownLabel(tasks[0].DueDate, today); got != "due today" {
+				t.Errorf("countdown = %q, want due today", got)
+			}
+		})
+	}
+}
+
+func TestOrderByClause(t *testing.T) {
+	db := newTestDB(t)
+	// Pairs of tasks tie on every sort field, so only the ID decides between them
+	for i := 0; i < 3; i++ {
+		for _, task := range []TodoItem{
+			{Title: "Alpha", Description: "one", DueDate: date(t, "2026-10-17"), Priority: 2},
+			{Title: "alpha", Description: "One", DueDate: date(t, "2026-10-17"), Priority: 2},
+			{Title: "beta", Description: "two", DueDate: date(t, "2026-10-18"), Priority: 1, State: StateDone},
+		} {
+			addTestTask(t, db, task)
+		}
+	}
+	if _, err := db.Exec("UPDATE todos SET created = '2026-10-01 12:00:00'"); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := func(tasks []TodoItem) []int {
+		result := []int{}
+		for _, task := range tasks {
+			result = append(result, task.ID)
+		}
+		return result
+	}
+
+	for _, sortBy := range []SortBy{SortByTitle, SortByDescription, SortByDueDate, SortByCreated, SortByStatus, SortByPriority} {
+		ascClause, ok := OrderByClause(sortBy, SortAsc)
+		if !ok {
+			t.Fatalf("sort %d has no ORDER BY", sortBy)
+		}
+		descClause, _ := OrderByClause(sortBy, SortDesc)
+
+		asc, err := LoadTasksSorted(db, "", ascClause)
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := LoadTasksSorted(db, "", descClause)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Flipping the order reverses the list exactly, ties included
+		reversed := ids(desc)
+		slices.Reverse(reversed)
+		if got := ids(asc); !slices.Equal(got, reversed) {
+			t.Errorf("%s: ascending %v is not descending %v reversed", ascClause, got, ids(desc))
+		}
+
+		// Every task has the same creation time, so ascending is plain ID order
+		if sortBy == SortByCreated && !slices.IsSorted(ids(asc)) {
+			t.Errorf("%s: tied tasks out of ID order: %v", ascClause, ids(asc))
+		}
+	}
+
+	// Fields sorted by parsed tags are left to Go
+	for _, sortBy := range []SortBy{SortByProject, SortByContext} {
+		if _, ok := OrderByClause(sortBy, SortAsc); ok {
+			t.Errorf("sort %d should not be done in SQL", sortBy)
+		}
+	}
+}
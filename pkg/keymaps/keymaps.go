@@ -6,6 +6,9 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 )
 
+// DisabledKey is the keymap value that unbinds an action
+const DisabledKey = "disabled"
+
 type KeyDefinition struct {
 	DefaultKey string
 	Help       string
@@ -14,7 +17,7 @@ type KeyDefinition struct {
 var KeyDefinitions = map[string]KeyDefinition{
 	"ShowHelp":           {"ctrl+b", "show/hide commands"},
 	"QuitApp":            {"q", "quit"},
-	"ToggleStatus":       {"x", "toggle status"},
+	"ToggleStatus":       {"x", "cycle status (todo/in progress/done)"},
 	"AddTask":            {"a", "add task"},
 	"EditTask":           {"e", "edit task"},
 	"DeleteTask":         {"d", "delete task"},
@@ -33,9 +36,53 @@ var KeyDefinitions = map[string]KeyDefinition{
 	"CalendarUp":         {"up", "move up in calendar"},
 	"CalendarDown":       {"down", "move down in calendar"},
 	"CalendarSelect":     {"enter", "select day in calendar"},
+	"CalendarLayout":     {"l", "switch the calendar between month and week layout"},
 	"ToggleSortBy":       {"s", "cycle sort by"},
 	"ToggleGroupBy":      {"g", "cycle group by"},
 	"ToggleSortOrder":    {"o", "toggle sort order"},
+	"ToggleDueOrder":     {"ctrl+o", "sort by due date, again to flip newest/oldest first"},
+	"ShareTasks":         {"m", "share tasks in view"},
+	"HistoryBack":        {"[", "back to previously viewed date"},
+	"HistoryForward":     {"]", "forward to next viewed date"},
+	"ZoomGroup":          {"enter", "zoom into project group (esc to return)"},
+	"AddFromTemplate":    {"t", "add task from template"},
+	"CopyView":           {"y", "copy tasks in view (then t: todo.txt, m: markdown)"},
+	"SortMenu":           {"S", "choose sort field from a menu"},
+	"PickRandomTask":     {"r", "pick a random undone task"},
+	"GotoTask":           {"#", "jump to a task by its ID"},
+	"RaisePriority":      {"+", "raise task priority"},
+	"LowerPriority":      {"-", "lower task priority (down to none)"},
+	"PickDate":           {"ctrl+d", "pick the due date from a calendar (form date field)"},
+	"SaveForm":           {"ctrl+s", "save the add/edit form from any field"},
+	"ProjectChips":       {"c", "toggle projects in view as filters from a chip bar"},
+	"FocusProject":       {"f", "focus on the selected task's project (again to clear)"},
+	"ToggleRowText":      {"v", "toggle showing titles or descriptions in rows"},
+	"ToggleIDs":          {"N", "show/hide task IDs in rows"},
+	"FilterGroups":       {"ctrl+g", "filter groups by name (esc to clear)"},
+	"PrevWeekWithTasks":  {"{", "nearest day with tasks a week or more back"},
+	"NextWeekWithTasks":  {"}", "nearest day with tasks a week or more ahead"},
+	"PrevMonthWithTasks": {"<", "nearest day with tasks a month or more back"},
+	"NextMonthWithTasks": {">", "nearest day with tasks a month or more ahead"},
+	"ReopenToToday":      {"R", "reopen a done task and move it to today"},
+	"ShowUntaggedTasks":  {"ctrl+t", "show only tasks without project or context"},
+	"ShowWaitingTasks":   {"ctrl+w", "show only tasks with the waiting context"},
+	"PinTask":            {"p", "pin/unpin task to the top of its views"},
+	"MarkWaiting":        {"w", "mark task as waiting, hidden until a follow-up date"},
+	"ToggleUTC":          {"Z", "switch between the configured time zone and UTC"},
+	"DeferOverdue":       {"D", "move all overdue tasks in view to today"},
+	"ExportTasks":        {"E", "export tasks to a file"},
+	"OpenView":           {"O", "open the tasks in view in $EDITOR or the default app"},
+	"ToggleWeekSidebar":  {"W", "show/hide pending task counts for the next 7 days"},
+	"SwitchDatabase":     {"L", "switch to another configured task list"},
+	"GrowTable":          {"ctrl+down", "show more task rows"},
+	"ShrinkTable":        {"ctrl+up", "show fewer task rows"},
+	"ResetView":          {"ctrl+r", "clear filter, search and grouping"},
+	"TrashView":          {"T", "show deleted tasks and restore them"},
+	"CopyToWeekday":      {"n", "copy task to the next occurrence of a weekday"},
+	"ToggleWeekView":     {"V", "toggle the view of the whole week"},
+	"ToggleReviewed":     {"i", "mark task as reviewed/unreviewed"},
+	"ShowUnreviewed":     {"I", "show only tasks not reviewed yet"},
+	"CommandPalette":     {":", "search and run any action"},
 }
 
 type KeyMap struct {
@@ -60,68 +107,205 @@ type KeyMap struct {
 	CalendarUp         key.Binding
 	CalendarDown       key.Binding
 	CalendarSelect     key.Binding
+	CalendarLayout     key.Binding
 	ToggleSortBy       key.Binding
 	ToggleGroupBy      key.Binding
 	ToggleSortOrder    key.Binding
+	ToggleDueOrder     key.Binding
+	ShareTasks         key.Binding
+	HistoryBack        key.Binding
+	HistoryForward     key.Binding
+	ZoomGroup          key.Binding
+	AddFromTemplate    key.Binding
+	CopyView           key.Binding
+	SortMenu           key.Binding
+	PickRandomTask     key.Binding
+	GotoTask           key.Binding
+	RaisePriority      key.Binding
+	LowerPriority      key.Binding
+	PickDate           key.Binding
+	SaveForm           key.Binding
+	FocusProject       key.Binding
+	ProjectChips       key.Binding
+	ToggleRowText      key.Binding
+	ToggleIDs          key.Binding
+	FilterGroups       key.Binding
+	PrevWeekWithTasks  key.Binding
+	NextWeekWithTasks  key.Binding
+	PrevMonthWithTasks key.Binding
+	NextMonthWithTasks key.Binding
+	ReopenToToday      key.Binding
+	ShowUntaggedTasks  key.Binding
+	ShowWaitingTasks   key.Binding
+	PinTask            key.Binding
+	MarkWaiting        key.Binding
+	ToggleUTC          key.Binding
+	DeferOverdue       key.Binding
+	ExportTasks        key.Binding
+	ToggleWeekSidebar  key.Binding
+	OpenView           key.Binding
+	SwitchDatabase     key.Binding
+	GrowTable          key.Binding
+	ShrinkTable        key.Binding
+	ResetView          key.Binding
+	TrashView          key.Binding
+	CopyToWeekday      key.Binding
+	ToggleWeekView     key.Binding
+	ToggleReviewed     key.Binding
+	ShowUnreviewed     key.Binding
+	CommandPalette     key.Binding
+
+	// Actions holds every binding by its name in KeyDefinitions
+	Actions map[string]key.Binding
 }
 
 func BuildKeyMap(configOverrides map[string]string) KeyMap {
-	km := KeyMap{}
+	km := KeyMap{Actions: make(map[string]key.Binding)}
 	for action, def := range KeyDefinitions {
 		keyStr := def.DefaultKey
 		if override, exists := configOverrides[action]; exists && override != "" {
 			keyStr = override
 		}
+		binding := parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+		km.Actions[action] = binding
 
 		switch action {
 		case "ShowHelp":
-			km.ShowHelp = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ShowHelp = binding
 		case "QuitApp":
-			km.QuitApp = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.QuitApp = binding
 		case "ToggleStatus":
-			km.ToggleStatus = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ToggleStatus = binding
 		case "AddTask":
-			km.AddTask = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.AddTask = binding
 		case "EditTask":
-			km.EditTask = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.EditTask = binding
 		case "DeleteTask":
-			km.DeleteTask = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.DeleteTask = binding
 		case "ToggleViewMode":
-			km.ToggleViewMode = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ToggleViewMode = binding
 		case "ShowDoneTasks":
-			km.ShowDoneTasks = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ShowDoneTasks = binding
 		case "ShowUndoneTasks":
-			km.ShowUndoneTasks = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ShowUndoneTasks = binding
 		case "SearchTasks":
-			km.SearchTasks = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.SearchTasks = binding
 		case "PrevDay":
-			km.PrevDay = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.PrevDay = binding
 		case "NextDay":
-			km.NextDay = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.NextDay = binding
 		case "PrevDayWithTasks":
-			km.PrevDayWithTasks = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.PrevDayWithTasks = binding
 		case "NextDayWithTasks":
-			km.NextDayWithTasks = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.NextDayWithTasks = binding
 		case "JumpToToday":
-			km.JumpToToday = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.JumpToToday = binding
 		case "ToggleCalendarView":
-			km.ToggleCalendarView = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ToggleCalendarView = binding
 		case "CalendarLeft":
-			km.CalendarLeft = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.CalendarLeft = binding
 		case "CalendarRight":
-			km.CalendarRight = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.CalendarRight = binding
 		case "CalendarUp":
-			km.CalendarUp = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.CalendarUp = binding
 		case "CalendarDown":
-			km.CalendarDown = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.CalendarDown = binding
+		case "CalendarLayout":
+			km.CalendarLayout = binding
 		case "CalendarSelect":
-			km.CalendarSelect = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.CalendarSelect = binding
 		case "ToggleSortBy":
-			km.ToggleSortBy = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ToggleSortBy = binding
 		case "ToggleGroupBy":
-			km.ToggleGroupBy = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ToggleGroupBy = binding
 		case "ToggleSortOrder":
-			km.ToggleSortOrder = parseKeyBinding(keyStr, def.DefaultKey, def.Help)
+			km.ToggleSortOrder = binding
+		case "ToggleDueOrder":
+			km.ToggleDueOrder = binding
+		case "ShareTasks":
+			km.ShareTasks = binding
+		case "HistoryBack":
+			km.HistoryBack = binding
+		case "HistoryForward":
+			km.HistoryForward = binding
+		case "ZoomGroup":
+			km.ZoomGroup = binding
+		case "AddFromTemplate":
+			km.AddFromTemplate = binding
+		case "CopyView":
+			km.CopyView = binding
+		case "SortMenu":
+			km.SortMenu = binding
+		case "PickRandomTask":
+			km.PickRandomTask = binding
+		case "GotoTask":
+			km.GotoTask = binding
+		case "RaisePriority":
+			km.RaisePriority = binding
+		case "LowerPriority":
+			km.LowerPriority = binding
+		case "PickDate":
+			km.PickDate = binding
+		case "SaveForm":
+			km.SaveForm = binding
+		case "ProjectChips":
+			km.ProjectChips = binding
+		case "FocusProject":
+			km.FocusProject = binding
+		case "ToggleRowText":
+			km.ToggleRowText = binding
+		case "ToggleIDs":
+			km.ToggleIDs = binding
+		case "FilterGroups":
+			km.FilterGroups = binding
+		case "PrevWeekWithTasks":
+			km.PrevWeekWithTasks = binding
+		case "NextWeekWithTasks":
+			km.NextWeekWithTasks = binding
+		case "PrevMonthWithTasks":
+			km.PrevMonthWithTasks = binding
+		case "NextMonthWithTasks":
+			km.NextMonthWithTasks = binding
+		case "ReopenToToday":
+			km.ReopenToToday = binding
+		case "ShowUntaggedTasks":
+			km.ShowUntaggedTasks = binding
+		case "ShowWaitingTasks":
+			km.ShowWaitingTasks = binding
+		case "PinTask":
+			km.PinTask = binding
+		case "MarkWaiting":
+			km.MarkWaiting = binding
+		case "ToggleUTC":
+			km.ToggleUTC = binding
+		case "DeferOverdue":
+			km.DeferOverdue = binding
+		case "ExportTasks":
+			km.ExportTasks = binding
+		case "OpenView":
+			km.OpenView = binding
+		case "ToggleWeekSidebar":
+			km.ToggleWeekSidebar = binding
+		case "SwitchDatabase":
+			km.SwitchDatabase = binding
+		case "GrowTable":
+			km.GrowTable = binding
+		case "ShrinkTable":
+			km.ShrinkTable = binding
+		case "ResetView":
+			km.ResetView = binding
+		case "TrashView":
+			km.TrashView = binding
+		case "CopyToWeekday":
+			km.CopyToWeekday = binding
+		case "ToggleWeekView":
+			km.ToggleWeekView = binding
+		case "ToggleReviewed":
+			km.ToggleReviewed = binding
+		case "ShowUnreviewed":
+			km.ShowUnreviewed = binding
+		case "CommandPalette":
+			km.CommandPalette = binding
 		}
 	}
 	return km
@@ -132,6 +316,14 @@ func parseKeyBinding(keyStr, defaultKey, helpText string) key.Binding {
 		keyStr = defaultKey
 	}
 
+	// An unbound action never matches a key and shows as disabled in the help view
+	if strings.EqualFold(strings.TrimSpace(keyStr), DisabledKey) {
+		return key.NewBinding(
+			key.WithHelp(DisabledKey, helpText),
+			key.WithDisabled(),
+		)
+	}
+
 	// Handle multiple keys separated by commas
 	keys := strings.Split(keyStr, ",")
 	for i, k := range keys {
@@ -0,0 +1,54 @@
+package keymaps
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func keyPress(s string) tea.KeyMsg {
+	if s == "delete" {
+		return tea.KeyMsg{Type: tea.KeyDelete}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestDisabledBinding(t *testing.T) {
+	km := BuildKeyMap(map[string]string{
+		"DeleteTask": DisabledKey,
+		"EditTask":   " Disabled ",
+		"AddTask":    "",
+	})
+
+	for name, binding := range map[string]key.Binding{
+		"DeleteTask":         km.DeleteTask,
+		"EditTask":           km.EditTask,
+		"Actions DeleteTask": km.Actions["DeleteTask"],
+	} {
+		if binding.Enabled() {
+			t.Errorf("%s is enabled", name)
+		}
+		if len(binding.Keys()) != 0 {
+			t.Errorf("%s still has keys %q", name, binding.Keys())
+		}
+		if binding.Help().Key != DisabledKey || binding.Help().Desc == "" {
+			t.Errorf("%s help = %+v, want it listed as disabled", name, binding.Help())
+		}
+	}
+
+	// Neither the default keys nor the word itself trigger a disabled action
+	for _, k := range []string{"d", "delete", "e", "disabled"} {
+		if key.Matches(keyPress(k), km.DeleteTask, km.EditTask) {
+			t.Errorf("%q matches a disabled action", k)
+		}
+	}
+
+	// An empty value keeps the default key, and other actions are untouched
+	if !key.Matches(keyPress(KeyDefinitions["AddTask"].DefaultKey), km.AddTask) {
+		t.Errorf("AddTask lost its default key %q", KeyDefinitions["AddTask"].DefaultKey)
+	}
+	if !key.Matches(keyPress("x"), km.ToggleStatus) {
+		t.Error("ToggleStatus no longer matches x")
+	}
+}
@@ -2,92 +2,1474 @@ package ui
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"awp/pkg/commands"
 	"awp/pkg/config"
 	"awp/pkg/database"
+	"awp/pkg/utils"
 )
 
+// viewClause builds the where clause of the view mode on viewDate with the active filter, search,
+// focus project and project chips, and its arguments
+func (m *Model) viewClause(viewMode database.ViewMode, viewDate string) (string, []interface{}) {
+	whereClause, args := database.BuildWhereClause(viewMode, m.taskFilter, viewDate, m.searchTerm, m.focusProject, m.today().Format("2006-01-02"))
+	if len(m.chipProjects) == 0 {
+		return whereClause, args
+	}
+
+	chipClause, chipArgs := database.ProjectsClause(m.chipProjects)
+	if whereClause == "" {
+		return chipClause, chipArgs
+	}
+	return whereClause + " AND " + chipClause, append(args, chipArgs...)
+}
+
+// viewDateString returns the date (YYYY-MM-DD) the current view is scoped to: the view date, or in
+// the calendar the displayed month, which navigating the calendar moves away from the view date
+func (m *Model) viewDateString() string {
+	switch m.viewMode {
+	case database.CalendarViewMode:
+		return m.calendarMonth.Format("2006-01-02")
+	case database.WeekViewMode:
+		return m.startOfWeek(m.viewDate).Format("2006-01-02")
+	}
+	return m.viewDate.Format("2006-01-02")
+}
+
 // loadTasks retrieves and displays tasks based on current filters
 func (m *Model) loadTasks() {
 	var items []database.TodoItem
 	var err error
 
-	// Build where clause using the database package function
-	dateStr := m.viewDate.Format("2006-01-02")
-	whereClause := database.BuildWhereClause(m.viewMode, m.taskFilter, dateStr, m.searchTerm)
+	// Build where clause using the database package function
+	whereClause, args := m.viewClause(m.viewMode, m.viewDateString())
+
+	// Without grouping, let SQLite sort when it can instead of sorting again in Go
+	orderBy, sqlSorted := database.OrderByClause(m.sortBy, m.sortOrder)
+	sqlSorted = sqlSorted && m.groupBy == database.GroupByNone
+
+	// Cap broad searches, counting the matches first to tell whether the cap is hit
+	limit := 0
+	m.searchMatches = 0
+	if m.searchTerm != "" && m.config.MaxSearchResults > 0 {
+		count, err := database.CountTasks(m.db, whereClause, args...)
+		if err != nil {
+			m.err = err
+			return
+		}
+		if count > m.config.MaxSearchResults {
+			limit = m.config.MaxSearchResults
+			m.searchMatches = count
+		}
+	}
+
+	// Load the tasks with the combined where clause
+	switch {
+	case limit > 0 && !sqlSorted:
+		items, err = database.LoadTasksLimited(m.db, whereClause, "duedate DESC, id DESC", limit, args...)
+	case limit > 0:
+		items, err = database.LoadTasksLimited(m.db, whereClause, orderBy, limit, args...)
+	case sqlSorted:
+		items, err = database.LoadTasksSorted(m.db, whereClause, orderBy, args...)
+	default:
+		items, err = database.LoadTasks(m.db, whereClause, args...)
+	}
+
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	// Remember where the cursor was in the view we are leaving
+	prevKey := m.viewKey
+	if idx := m.getSelectedItemIndex(); prevKey != "" && idx >= 0 && idx < len(m.items) {
+		m.cursorMemory[prevKey] = m.items[idx].ID
+	}
+
+	// Pinned tasks in the view show at the top; pinning only changes the order, not what is shown
+	pinnedClause := "pinned = 1"
+	if whereClause != "" {
+		pinnedClause += " AND (" + whereClause + ")"
+	}
+	pinned, err := database.LoadTasks(m.db, pinnedClause, args...)
+	if err != nil {
+		m.err = err
+		return
+	}
+	pinned = m.SortTasks(pinned)
+
+	var unpinned []database.TodoItem
+	for _, item := range items {
+		if !item.Pinned {
+			unpinned = append(unpinned, item)
+		}
+	}
+
+	// Apply grouping and sorting
+	groupedTasks := []GroupedTasks{{GroupName: "", Tasks: unpinned}}
+	if !sqlSorted {
+		groupedTasks = m.GroupTasks(unpinned)
+	}
+
+	// Re-populate m.items with the tasks in the order they appear in the table
+	sortedItems := append([]database.TodoItem{}, pinned...)
+	for _, group := range groupedTasks {
+		sortedItems = append(sortedItems, group.Tasks...)
+	}
+	m.items = sortedItems
+
+	tableRows := []table.Row{}
+	rowItems := []int{}
+	rowGroups := make(map[int]string)
+	itemIdx := 0
+
+	for _, item := range pinned {
+		tableRows = append(tableRows, m.taskRow(item))
+		rowItems = append(rowItems, itemIdx)
+		itemIdx++
+	}
+	if len(pinned) > 0 && m.groupBy != database.GroupByNone {
+		tableRows = append(tableRows, m.spanningRow(""))
+		rowItems = append(rowItems, -1)
+	}
+
+	for _, group := range groupedTasks {
+		// Add group header if grouping is enabled
+		if m.groupBy != database.GroupByNone {
+			tableRows = append(tableRows, m.spanningRow(
+				lipgloss.NewStyle().
+					Bold(true).
+					Foreground(lipgloss.Color(m.styles.GroupHeaderColor)).
+					Render(m.groupHeader(group)),
+			))
+			rowGroups[len(rowItems)] = group.GroupName
+			rowItems = append(rowItems, -1)
+		}
+
+		// Add tasks in the group
+		for _, item := range group.Tasks {
+			tableRows = append(tableRows, m.taskRow(item))
+			rowItems = append(rowItems, itemIdx)
+			itemIdx++
+		}
+
+		// Add separator between groups
+		if m.groupBy != database.GroupByNone && len(groupedTasks) > 1 && m.config.GroupSeparator != "none" {
+			tableRows = append(tableRows, m.spanningRow(m.groupSeparator()))
+			rowItems = append(rowItems, -1)
+		}
+	}
+
+	m.table.SetRows(tableRows)
+	m.rowItems = rowItems
+	m.rowGroups = rowGroups
+
+	// Restore the remembered cursor when switching to a different view
+	m.viewKey = m.viewSignature()
+	if m.viewKey != prevKey {
+		m.restoreCursor()
+	}
+}
+
+// taskRow builds the table row for a task, either as one combined cell or one cell per configured column
+func (m *Model) taskRow(item database.TodoItem) table.Row {
+	if len(m.config.Columns) == 0 {
+		text := m.followUpMarker(item) + m.displayText(item)
+		if item.Priority > 0 {
+			text = m.priorityMarker(item.Priority) + " " + text
+		}
+		text += m.staleSuffix(item)
+		if m.config.ShowCountdown && !item.Status {
+			text += " " + m.countdown(item)
+		}
+		return table.Row{fmt.Sprintf("%s%s%s %s", m.idPrefix(item), m.rowDatePrefix(item), m.statusCell(item), text)}
+	}
+
+	row := make(table.Row, 0, len(m.config.Columns))
+	for _, column := range m.config.Columns {
+		row = append(row, m.cellValue(item, column))
+	}
+	return row
+}
+
+// idPrefix renders a dim "#12 " task ID before a combined row while IDs are shown
+func (m *Model) idPrefix(item database.TodoItem) string {
+	if !m.showIDs {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.RowDateColor)).Render(fmt.Sprintf("#%d", item.ID)) + " "
+}
+
+// rowDatePrefix renders a subtle "MM-DD " due date before a combined row when show_date_in_rows asks
+// for it, padded for undated tasks so the rows stay aligned
+func (m *Model) rowDatePrefix(item database.TodoItem) string {
+	switch m.config.ShowDateInRows {
+	case "always":
+	case "on":
+		if m.viewMode == database.TodayViewMode {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	date := "     "
+	if !item.DueDate.IsZero() {
+		date = item.DueDate.Format("01-02")
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.RowDateColor)).Render(date) + " "
+}
+
+// cellValue renders a single column field of a task
+func (m *Model) cellValue(item database.TodoItem, column string) string {
+	switch column {
+	case "status":
+		return m.statusCell(item)
+	case "id":
+		return fmt.Sprintf("%d", item.ID)
+	case "priority":
+		return m.priorityMarker(item.Priority)
+	case "due":
+		if item.DueDate.IsZero() {
+			return ""
+		}
+		return m.dueDateStyle(item).Render(item.DueDate.Format("2006-01-02"))
+	case "countdown":
+		if item.Status {
+			return ""
+		}
+		return m.countdown(item)
+	case "created":
+		return item.Created.In(utils.Location()).Format("2006-01-02")
+	case "projects":
+		return prefixTags("+", item.Projects)
+	case "contexts":
+		return prefixTags("@", item.Contexts)
+	case "description":
+		return m.highlightProjectsAndContexts(item.Description)
+	default:
+		return m.followUpMarker(item) + m.displayText(item) + m.staleSuffix(item)
+	}
+}
+
+// displayText returns the highlighted primary text of a task (its title, or description if untitled)
+func (m *Model) displayText(item database.TodoItem) string {
+	primary, fallback := item.Title, item.Description
+	if m.showDescriptions {
+		primary, fallback = fallback, primary
+	}
+
+	text := fallback
+	if primary != "" {
+		text = primary
+	}
+	return m.highlightProjectsAndContexts(text)
+}
+
+// spanningRow builds a row showing text in the first column and leaving the others empty
+func (m *Model) spanningRow(text string) table.Row {
+	columns := len(m.config.Columns)
+	if columns == 0 {
+		columns = 1
+	}
+	row := make(table.Row, columns)
+	row[0] = text
+	return row
+}
+
+// prefixTags renders tags with their + or @ prefix separated by spaces
+func prefixTags(prefix string, tags []string) string {
+	var parts []string
+	for _, tag := range tags {
+		parts = append(parts, prefix+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+// groupHeader renders the configured header text for a group
+func (m *Model) groupHeader(group GroupedTasks) string {
+	format := m.config.GroupHeaderFormat
+	if format == "" {
+		format = "== {name} ({count}) =="
+	}
+	return strings.NewReplacer(
+		"{name}", group.GroupName,
+		"{count}", fmt.Sprintf("%d", len(group.Tasks)),
+	).Replace(format)
+}
+
+// groupSeparator renders the row placed between groups
+func (m *Model) groupSeparator() string {
+	if m.config.GroupSeparator != "rule" {
+		return ""
+	}
+
+	width := m.table.Width() - 2
+	if width < 1 {
+		width = 1
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.styles.GroupSeparatorColor)).
+		Render(strings.Repeat(lipgloss.NormalBorder().Top, width))
+}
+
+// resetView clears the parts of the view listed in reset_view_includes, keeping the sort order
+func (m *Model) resetView() {
+	for _, part := range m.config.ResetViewIncludes {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "filter":
+			m.taskFilter = database.AllTasksFilter
+		case "search":
+			m.searchTerm = ""
+			m.searchInput.SetValue("")
+			m.zoomPrev = nil // A zoomed group is a search too
+		case "group":
+			m.groupBy = database.GroupByNone
+			m.groupFilter = ""
+		case "focus":
+			m.focusProject = ""
+		case "chips":
+			m.chipProjects = nil
+		}
+	}
+	m.loadTasks()
+	m.statusMsg = "Filters cleared"
+}
+
+// zoomIntoGroup shows only the project of the group header under the cursor, across all dates
+func (m *Model) zoomIntoGroup() {
+	if m.groupBy != database.GroupByProject {
+		return
+	}
+
+	// Only group headers can be zoomed into, not task rows
+	groupName, ok := m.rowGroups[m.table.Cursor()]
+	if !ok || !strings.HasPrefix(groupName, "+") {
+		return
+	}
+
+	m.zoomPrev = &savedView{
+		viewMode:   m.viewMode,
+		taskFilter: m.taskFilter,
+		viewDate:   m.viewDate,
+		searchTerm: m.searchTerm,
+		groupBy:    m.groupBy,
+	}
+
+	m.viewMode = database.AllViewMode
+	m.groupBy = database.GroupByNone
+	m.searchTerm = groupName
+	m.searchInput.SetValue(groupName)
+	m.loadTasks()
+}
+
+// zoomOut restores the view that was active before zooming into a group
+func (m *Model) zoomOut() {
+	prev := m.zoomPrev
+	m.zoomPrev = nil
+
+	m.viewMode = prev.viewMode
+	m.taskFilter = prev.taskFilter
+	m.viewDate = prev.viewDate
+	m.searchTerm = prev.searchTerm
+	m.searchInput.SetValue(prev.searchTerm)
+	m.groupBy = prev.groupBy
+	m.loadTasks()
+}
+
+// viewSignature identifies the current combination of view mode, date, filter and search
+func (m *Model) viewSignature() string {
+	dateKey := ""
+	if m.viewMode == database.TodayViewMode || m.viewMode == database.WeekViewMode {
+		dateKey = m.viewDateString()
+	}
+	return fmt.Sprintf("%d|%s|%d|%s|%s", m.viewMode, dateKey, m.taskFilter, m.searchTerm, m.focusProject)
+}
+
+// restoreCursor moves the cursor to the task remembered for the current view, or to the top
+func (m *Model) restoreCursor() {
+	if id, ok := m.cursorMemory[m.viewKey]; ok {
+		for idx, item := range m.items {
+			if item.ID == id {
+				m.selectItem(idx)
+				return
+			}
+		}
+	}
+	m.table.SetCursor(0)
+}
+
+// advancePast moves the cursor to the task after the one with the given ID. If that task
+// is no longer in view (e.g. filtered out once done), the cursor already rests on the next one.
+func (m *Model) advancePast(id int) {
+	for idx, item := range m.items {
+		if item.ID == id {
+			if idx+1 < len(m.items) {
+				m.selectItem(idx + 1)
+			}
+			return
+		}
+	}
+}
+
+// selectItem moves the table cursor to the row showing m.items[idx]
+func (m *Model) selectItem(idx int) {
+	for row, itemIdx := range m.rowItems {
+		if itemIdx == idx {
+			m.table.SetCursor(row)
+			return
+		}
+	}
+}
+
+// today returns the current time adjusted for the configured end-of-day cutoff
+func (m *Model) today() time.Time {
+	return utils.Today(m.config.DayCutoffHour)
+}
+
+// alertOverdue rings the terminal bell and sets the startup banner if any tasks are overdue
+func (m *Model) alertOverdue() {
+	clause, args := database.OverdueClause(m.today().Format("2006-01-02"), m.config.OverdueGraceDays)
+	count, err := database.CountTasks(m.db, clause, args...)
+	if err != nil {
+		utils.Log("Error counting overdue tasks: %v", err)
+		return
+	}
+	if count == 0 {
+		return
+	}
+
+	fmt.Print("\a")
+	m.ShowBanner(fmt.Sprintf("%d overdue task(s) - press any key to dismiss", count))
+}
+
+// ShowBanner adds a line to the startup banner, which is cleared by the first key press
+func (m *Model) ShowBanner(text string) {
+	if m.banner != "" {
+		m.banner += "\n"
+	}
+	m.banner += " " + text + " "
+}
+
+// stepDay returns the day before (dir -1) or after (dir 1) date, skipping non-working days if enabled
+func (m *Model) stepDay(date time.Time, dir int) time.Time {
+	next := date.AddDate(0, 0, dir)
+	if !m.config.SkipWeekends {
+		return next
+	}
+
+	// Give up after a week in case every day is configured as non-working
+	for i := 0; i < 7 && m.isNonWorkingDay(next); i++ {
+		next = next.AddDate(0, 0, dir)
+	}
+	return next
+}
+
+// isNonWorkingDay reports whether date falls on one of the configured non-working weekdays
+func (m *Model) isNonWorkingDay(date time.Time) bool {
+	weekday := strings.ToLower(date.Weekday().String())
+	for _, day := range m.config.NonWorkingDays {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if len(day) >= 3 && strings.HasPrefix(weekday, day) {
+			return true
+		}
+	}
+	return false
+}
+
+// weekStart returns the configured first day of the week
+func (m *Model) weekStart() time.Weekday {
+	if strings.EqualFold(strings.TrimSpace(m.config.WeekStart), "monday") {
+		return time.Monday
+	}
+	return time.Sunday
+}
+
+// startOfWeek returns the first day of the week containing date, by the configured week start
+func (m *Model) startOfWeek(date time.Time) time.Time {
+	return date.AddDate(0, 0, -((int(date.Weekday()) - int(m.weekStart()) + 7) % 7))
+}
+
+// weekNumber returns the ISO week number of the week containing date. A week starting on Sunday
+// gets the number of the ISO week most of its days fall in.
+func (m *Model) weekNumber(date time.Time) int {
+	_, week := m.startOfWeek(date).AddDate(0, 0, 3).ISOWeek()
+	return week
+}
+
+// toggleUTC switches between the configured time zone and UTC for deciding which day it is
+func (m *Model) toggleUTC() {
+	m.useUTC = !m.useUTC
+	if m.useUTC {
+		utils.SetLocation(time.UTC)
+		m.statusMsg = "Dates now follow UTC"
+	} else {
+		utils.SetLocation(m.homeLocation)
+		m.statusMsg = fmt.Sprintf("Dates now follow %s", m.homeLocation)
+	}
+}
+
+// minTableHeight is the fewest rows the task table shrinks to
+const minTableHeight = 3
+
+// applyTableHeight sizes the table to the terminal height, adjusted by the rows added or taken
+// away, keeping it between minTableHeight and the terminal height
+func (m *Model) applyTableHeight() {
+	height := m.height - 4 + m.tableHeightAdjust
+	height = min(max(height, minTableHeight), max(m.height-2, minTableHeight))
+	m.table.SetHeight(height)
+}
+
+// resizeTable grows (delta > 0) or shrinks the table by delta rows, giving the space to or taking
+// it from the status lines
+func (m *Model) resizeTable(delta int) {
+	before := m.table.Height()
+	m.tableHeightAdjust += delta
+	m.applyTableHeight()
+	if m.table.Height() == before {
+		// Don't build up adjustment past the limits
+		m.tableHeightAdjust -= delta
+	}
+	m.statusMsg = fmt.Sprintf("Table height: %d rows", m.table.Height())
+}
+
+// isPastDate reports whether date falls on a day before today
+func (m *Model) isPastDate(date time.Time) bool {
+	return date.Format("2006-01-02") < m.today().Format("2006-01-02")
+}
+
+// isOverdue reports whether a task counts as overdue, honoring the configured grace period
+func (m *Model) isOverdue(item database.TodoItem) bool {
+	return item.IsOverdue(m.today(), m.config.OverdueGraceDays)
+}
+
+// reopenToToday marks the selected done task as todo again and moves it to today
+func (m *Model) reopenToToday() {
+	idx := m.getSelectedItemIndex()
+	if idx < 0 || idx >= len(m.items) {
+		m.statusMsg = "No task selected"
+		return
+	}
+
+	item := m.items[idx]
+	if !item.Status {
+		m.statusMsg = "Task is not done - nothing to reopen"
+		return
+	}
+
+	item.SetState(database.StateTodo)
+	item.DueDate = m.today()
+	if err := database.UpdateTask(m.db, item); err != nil {
+		m.writeFailed(err)
+		return
+	}
+	m.loadTasks()
+	m.restoreSelection(item.ID)
+	m.statusMsg = fmt.Sprintf("Reopened for today: %s", item.Title)
+}
+
+// gotoTask moves the cursor to the task whose ID was entered. A task outside the current view is
+// shown on its due date, or in the unfiltered all-tasks view if the date view would still hide it.
+func (m *Model) gotoTask() {
+	id, err := strconv.Atoi(strings.TrimSpace(m.gotoInput.Value()))
+	if err != nil || id <= 0 {
+		m.statusMsg = "Enter a numeric task ID"
+		return
+	}
+
+	task, err := database.LoadTaskByID(m.db, id)
+	if err != nil {
+		m.err = err
+		return
+	}
+	if task == nil {
+		m.statusMsg = fmt.Sprintf("No task with ID %d", id)
+		return
+	}
+
+	m.mode = NormalMode
+	m.gotoInput.Blur()
+
+	if m.restoreSelection(id) {
+		return
+	}
+
+	if !task.DueDate.IsZero() {
+		m.viewMode = database.TodayViewMode
+		m.setViewDate(task.DueDate)
+		m.loadTasks()
+		if m.restoreSelection(id) {
+			return
+		}
+	}
+
+	m.viewMode = database.AllViewMode
+	m.taskFilter = database.AllTasksFilter
+	m.searchTerm = ""
+	m.searchInput.SetValue("")
+	m.focusProject = ""
+	m.loadTasks()
+	if !m.restoreSelection(id) && task.IsWaiting(m.today()) {
+		m.statusMsg = fmt.Sprintf("Task %d is waiting until %s", id, task.WaitingUntil.Format("2006-01-02"))
+	}
+}
+
+// openChipBar shows the projects of the current view as numbered chips to toggle as filters
+func (m *Model) openChipBar() {
+	// Offer every project of the view without the chip filter, so active chips can be combined
+	whereClause, args := database.BuildWhereClause(m.viewMode, m.taskFilter, m.viewDateString(), m.searchTerm, m.focusProject, m.today().Format("2006-01-02"))
+	projects, err := database.DistinctProjects(m.db, whereClause, args...)
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	// Keep active chips even if no task in view has them anymore
+	for _, project := range m.chipProjects {
+		if !slices.Contains(projects, project) {
+			projects = append(projects, project)
+		}
+	}
+	if len(projects) == 0 {
+		m.statusMsg = "No projects in view"
+		return
+	}
+	if len(projects) > 9 {
+		projects = projects[:9]
+	}
+
+	m.chipNames = projects
+	m.chipBarOpen = true
+}
+
+// toggleChip adds the project of the numbered chip to the filter, or removes it; 0 clears all chips
+func (m *Model) toggleChip(keyStr string) {
+	if keyStr == "0" {
+		m.chipProjects = nil
+		m.loadTasks()
+		return
+	}
+
+	idx := int(keyStr[0] - '1')
+	if idx >= len(m.chipNames) {
+		return
+	}
+
+	project := m.chipNames[idx]
+	if i := slices.Index(m.chipProjects, project); i >= 0 {
+		m.chipProjects = slices.Delete(m.chipProjects, i, i+1)
+	} else {
+		m.chipProjects = append(m.chipProjects, project)
+	}
+	m.loadTasks()
+}
+
+// writeFailed reports a failed database write and reloads the list, so it shows what the database
+// holds rather than the change that was attempted
+func (m *Model) writeFailed(err error) {
+	utils.Log("Error writing to the database: %v", err)
+	m.loadTasks()
+	m.err = err
+}
+
+// noteCleared is called after a task was completed or deleted. It celebrates a view without open
+// tasks left, if enabled, and names the task's projects that have no tasks (or, after completing, no
+// open tasks) left. Such projects need no cleanup: projects only exist as tags on tasks.
+func (m *Model) noteCleared(task database.TodoItem, deleted bool) {
+	var notes []string
+
+	if m.config.CelebrateEmptyView && !task.Status {
+		open := 0
+		for _, item := range m.items {
+			if !item.Status {
+				open++
+			}
+		}
+		if open == 0 {
+			notes = append(notes, "All clear - nothing left to do here!")
+		}
+	}
+
+	var emptied []string
+	for _, project := range task.Projects {
+		clause, args := database.ProjectsClause([]string{project})
+		if !deleted {
+			clause += " AND status = 0"
+		}
+		count, err := database.CountTasks(m.db, clause, args...)
+		if err != nil {
+			utils.Log("Error counting tasks of +%s: %v", project, err)
+			continue
+		}
+		if count == 0 {
+			emptied = append(emptied, "+"+project)
+		}
+	}
+	if len(emptied) > 0 {
+		left := "no open tasks left"
+		if deleted {
+			left = "no tasks left"
+		}
+		notes = append(notes, fmt.Sprintf("%s: %s", strings.Join(emptied, ", "), left))
+	}
+
+	if len(notes) > 0 {
+		m.statusMsg = strings.Join(notes, " | ")
+	}
+}
+
+// confirmLargeAllView asks before switching to the all-tasks view when it would load more tasks than
+// the configured threshold. It returns true if the switch now waits for the answer.
+func (m *Model) confirmLargeAllView() bool {
+	if m.config.LargeViewThreshold <= 0 {
+		return false
+	}
+
+	whereClause, args := m.viewClause(database.AllViewMode, "")
+	count, err := database.CountTasks(m.db, whereClause, args...)
+	if err != nil {
+		utils.Log("Error counting tasks: %v", err)
+		return false
+	}
+	if count <= m.config.LargeViewThreshold {
+		return false
+	}
+
+	m.pendingAllView = true
+	m.statusMsg = fmt.Sprintf("Load all %d tasks? y/n", count)
+	return true
+}
+
+// overdueInViewClause matches the undone, overdue tasks of the current view and returns the
+// clause's arguments
+func (m *Model) overdueInViewClause() (string, []interface{}) {
+	clause, args := database.OverdueClause(m.today().Format("2006-01-02"), m.config.OverdueGraceDays)
+	viewClause, viewArgs := m.viewClause(m.viewMode, m.viewDateString())
+	if viewClause != "" {
+		clause = "(" + viewClause + ") AND " + clause
+		args = append(viewArgs, args...)
+	}
+	return clause, args
+}
+
+// deferTarget returns the day overdue tasks are moved to
+func (m *Model) deferTarget() time.Time {
+	if m.config.DeferOverdueTo == "tomorrow" {
+		return m.today().AddDate(0, 0, 1)
+	}
+	return m.today()
+}
+
+// askDeferOverdue counts the overdue tasks in view and asks before moving them
+func (m *Model) askDeferOverdue() {
+	clause, args := m.overdueInViewClause()
+	count, err := database.CountTasks(m.db, clause, args...)
+	if err != nil {
+		m.err = err
+		return
+	}
+	if count == 0 {
+		m.statusMsg = "No overdue tasks in view"
+		return
+	}
+
+	m.pendingDefer = count
+	m.statusMsg = fmt.Sprintf("Move %d overdue task(s) to %s? y/n", count, m.deferTarget().Format("2006-01-02"))
+}
+
+// deferOverdue moves the overdue tasks in view in one update
+func (m *Model) deferOverdue() {
+	target := m.deferTarget()
+	clause, args := m.overdueInViewClause()
+	moved, err := database.MoveTasksDue(m.db, clause, target, args...)
+	if err != nil {
+		m.writeFailed(err)
+		return
+	}
+	m.loadTasks()
+	m.statusMsg = fmt.Sprintf("Moved %d task(s) to %s", moved, target.Format("2006-01-02"))
+}
+
+// exportTasks writes the tasks in view, or all tasks, to a timestamped file in the export directory
+func (m *Model) exportTasks(exportType string) {
+	tasks := m.items
+	if m.exportAllTasks {
+		var err error
+		tasks, err = database.LoadTasks(m.db, "")
+		if err != nil {
+			m.err = err
+			return
+		}
+	}
+
+	content, err := commands.FormatTasks(tasks, exportType)
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	dir, err := utils.ExpandHome(m.config.ExportDir)
+	if err != nil {
+		m.err = err
+		return
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			m.err = err
+			return
+		}
+	}
+
+	name := fmt.Sprintf("awp-export-%s.%s", utils.Now().Format("20060102-150405"), commands.ExportFileExtension(exportType))
+	path, err := filepath.Abs(filepath.Join(dir, name))
+	if err != nil {
+		m.err = err
+		return
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		m.err = err
+		return
+	}
+
+	m.statusMsg = fmt.Sprintf("Exported %d task(s) to %s", len(tasks), path)
+}
+
+// databaseNames returns the names of the configured databases in menu order
+func (m *Model) databaseNames() []string {
+	names := make([]string, 0, len(m.config.Databases))
+	for name := range m.config.Databases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// activeDatabaseName returns the configured name of the open database, or "" if it has none
+func (m *Model) activeDatabaseName() string {
+	for _, name := range m.databaseNames() {
+		if m.config.Databases[name] == m.config.Database {
+			return name
+		}
+	}
+	return ""
+}
+
+// switchDatabase closes the open database and opens the one configured under name, starting over
+// on today's tasks. On failure the open database is kept.
+func (m *Model) switchDatabase(name string) {
+	path := m.config.Databases[name]
+	if path == m.config.Database {
+		m.statusMsg = fmt.Sprintf("Already on %s", name)
+		return
+	}
+
+	db, err := database.ConnectDB(path, m.config.ReadOnly)
+	if err != nil {
+		m.err = err
+		return
+	}
+	if err := database.EnsureSchema(db, m.config.ReadOnly); err != nil {
+		db.Close()
+		m.err = fmt.Errorf("could not open %s: %w", name, err)
+		return
+	}
+
+	if err := m.db.Close(); err != nil {
+		utils.Log("Error closing database: %v", err)
+	}
+	m.db = db
+	m.config.Database = path
+	m.err = nil
+
+	// Task IDs of the previous list mean nothing in this one
+	m.cursorMemory = make(map[string]int)
+	m.viewKey = ""
+	m.zoomPrev = nil
+	m.focusProject = ""
+	m.chipProjects = nil
+	m.loadTodaysTasks()
+	m.statusMsg = fmt.Sprintf("Switched to %s", name)
+}
+
+// formActions are the actions bound only inside the add/edit form. The palette runs actions by
+// pressing their key in the task list, where these keys mean something else (or nothing).
+var formActions = map[string]bool{
+	"PickDate": true,
+	"SaveForm": true,
+}
+
+// calendarActions are the actions that only do something in the calendar view
+var calendarActions = map[string]bool{
+	"CalendarLeft":   true,
+	"CalendarRight":  true,
+	"CalendarUp":     true,
+	"CalendarDown":   true,
+	"CalendarSelect": true,
+	"CalendarLayout": true,
+}
+
+// actionApplies reports whether pressing the action's key in the task list runs that action, which
+// is how the palette runs it. Form actions never do, and the calendar takes over some keys.
+func (m *Model) actionApplies(action string) bool {
+	if formActions[action] {
+		return false
+	}
+	inCalendar := m.viewMode == database.CalendarViewMode
+	if calendarActions[action] {
+		return inCalendar
+	}
+	// The calendar selects its day with the key that zooms into a group elsewhere
+	return action != "ZoomGroup" || !inCalendar
+}
+
+// paletteActions returns the names of the enabled actions that apply to the current view and whose
+// help text or name contains the palette input's letters in order, sorted by help text with exact
+// matches first
+func (m *Model) paletteActions() []string {
+	typed := strings.ToLower(strings.TrimSpace(m.paletteInput.Value()))
+	query := strings.ReplaceAll(typed, " ", "")
+
+	var actions []string
+	for action, binding := range m.keyMap.Actions {
+		if action == "CommandPalette" || !binding.Enabled() || !m.actionApplies(action) {
+			continue
+		}
+		if fuzzyMatch(strings.ToLower(binding.Help().Desc+action), query) {
+			actions = append(actions, action)
+		}
+	}
+	// Actions containing the query as typed come before looser matches
+	exact := func(action string) bool {
+		return strings.Contains(strings.ToLower(m.keyMap.Actions[action].Help().Desc+" "+action), typed)
+	}
+	slices.SortFunc(actions, func(a, b string) int {
+		if exact(a) != exact(b) {
+			if exact(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(m.keyMap.Actions[a].Help().Desc, m.keyMap.Actions[b].Help().Desc)
+	})
+	return actions
+}
+
+// fuzzyMatch reports whether the letters of query appear in text in the same order
+func fuzzyMatch(text, query string) bool {
+	for _, r := range query {
+		i := strings.IndexRune(text, r)
+		if i < 0 {
+			return false
+		}
+		text = text[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// keyMsgFor returns a key message for the named key (such as "x" or "ctrl+r"). key.Matches compares
+// key names, so the message triggers the bindings of that key.
+func keyMsgFor(name string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// openTrash loads the deleted tasks, newest first, and shows them in the trash view
+func (m *Model) openTrash() {
+	items, err := database.LoadTrash(m.db)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.trashItems = items
+	m.trashCursor = 0
+	m.mode = TrashMode
+}
+
+// restoreFromTrash takes the selected task out of the trash and back into the task list
+func (m *Model) restoreFromTrash() {
+	if len(m.trashItems) == 0 {
+		return
+	}
+	if m.config.ReadOnly {
+		m.statusMsg = "Read-only: tasks cannot be restored"
+		return
+	}
+
+	item := m.trashItems[m.trashCursor]
+	if err := database.RestoreTask(m.db, item.ID); err != nil {
+		m.writeFailed(err)
+		return
+	}
+	utils.Log("Restored task ID: %d", item.ID)
+
+	m.trashItems = append(m.trashItems[:m.trashCursor], m.trashItems[m.trashCursor+1:]...)
+	if m.trashCursor >= len(m.trashItems) && m.trashCursor > 0 {
+		m.trashCursor--
+	}
+	m.loadTasks()
+	m.statusMsg = fmt.Sprintf("Restored: %s", item.Title)
+}
+
+// editorFinishedMsg reports the end of an editor started to open the view
+type editorFinishedMsg struct {
+	err error
+}
+
+// openView writes the tasks in view to a temporary file and opens it in $EDITOR, suspending the
+// TUI until the editor exits, or in the default app when $EDITOR is not set
+func (m *Model) openView() tea.Cmd {
+	if len(m.items) == 0 {
+		m.statusMsg = "Nothing to open"
+		return nil
+	}
+
+	format := m.config.OpenViewFormat
+	if format != "md" && format != "json" {
+		m.err = fmt.Errorf("unknown open_view_format %q (use md or json)", format)
+		return nil
+	}
+
+	content, err := commands.FormatTasks(m.items, format)
+	if err != nil {
+		m.err = err
+		return nil
+	}
+
+	file, err := os.CreateTemp("", "awp-view-*."+commands.ExportFileExtension(format))
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	m.openedViewFiles = append(m.openedViewFiles, file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		m.err = err
+		return nil
+	}
+	if err := file.Close(); err != nil {
+		m.err = err
+		return nil
+	}
+
+	if editor := strings.Fields(os.Getenv("EDITOR")); len(editor) > 0 {
+		cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+		return tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return editorFinishedMsg{err: err}
+		})
+	}
+
+	if err := utils.OpenURL(file.Name()); err != nil {
+		m.statusMsg = fmt.Sprintf("Could not open %s: %v", file.Name(), err)
+		return nil
+	}
+	m.statusMsg = fmt.Sprintf("Opened %d task(s) from %s", len(m.items), file.Name())
+	return nil
+}
+
+// Cleanup closes the database, which may have been switched from the one opened at startup, and
+// removes the temporary files written to open the view, unless keep_opened_views is set
+func (m Model) Cleanup() {
+	if err := m.db.Close(); err != nil {
+		utils.Log("Error closing database: %v", err)
+	}
+
+	if m.config.KeepOpenedViews {
+		return
+	}
+	for _, path := range m.openedViewFiles {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			utils.Log("Error removing %s: %v", path, err)
+		}
+	}
+}
+
+// toggleProjectFocus pins the view to the selected task's first project, or clears an active focus
+func (m *Model) toggleProjectFocus() {
+	if m.focusProject != "" {
+		m.focusProject = ""
+		m.loadTasks()
+		return
+	}
 
-	// Load the tasks with the combined where clause
-	items, err = database.LoadTasks(m.db, whereClause)
+	idx := m.getSelectedItemIndex()
+	if idx < 0 || idx >= len(m.items) || len(m.items[idx].Projects) == 0 {
+		m.statusMsg = "Select a task with a +project to focus on"
+		return
+	}
 
-	if err != nil {
-		m.err = err
+	m.focusProject = m.items[idx].Projects[0]
+	m.loadTasks()
+}
+
+// dueDateStyle returns the style for a task's due date, shaded by how soon it is due if enabled
+func (m *Model) dueDateStyle(item database.TodoItem) lipgloss.Style {
+	style := lipgloss.NewStyle()
+	if !m.config.ColorDueDates || item.Status || item.DueDate.IsZero() {
+		return style
+	}
+
+	now := m.today()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	due := time.Date(item.DueDate.Year(), item.DueDate.Month(), item.DueDate.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case m.isOverdue(item):
+		return style.Foreground(lipgloss.Color(m.styles.DueOverdueColor))
+	case due.Equal(today):
+		return style.Foreground(lipgloss.Color(m.styles.DueTodayColor))
+	case due.After(today) && due.Before(today.AddDate(0, 0, 7)):
+		return style.Foreground(lipgloss.Color(m.styles.DueThisWeekColor))
+	}
+	return style
+}
+
+// countdown renders how far off the task's due date is, colored by urgency: overdue, due today or
+// due within the week
+func (m *Model) countdown(item database.TodoItem) string {
+	label := utils.CountdownLabel(item.DueDate, m.today())
+	if item.DueDate.IsZero() {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.StaleColor)).Render(label)
+	}
+
+	due, today := utils.DateOnly(item.DueDate), utils.DateOnly(m.today())
+	style := lipgloss.NewStyle()
+	switch {
+	case m.isOverdue(item):
+		style = style.Foreground(lipgloss.Color(m.styles.DueOverdueColor))
+	case due.Equal(today):
+		style = style.Foreground(lipgloss.Color(m.styles.DueTodayColor))
+	case due.After(today) && due.Before(today.AddDate(0, 0, 7)):
+		style = style.Foreground(lipgloss.Color(m.styles.DueThisWeekColor))
+	}
+	return style.Render(label)
+}
+
+// staleSuffix renders a dim " (45d)" age for undone tasks open longer than the configured number of days
+func (m *Model) staleSuffix(item database.TodoItem) string {
+	if m.config.StaleAfterDays <= 0 || item.Status || item.Created.IsZero() {
+		return ""
+	}
+
+	now := m.today()
+	created := item.Created.In(now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	createdDay := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)
+	age := int(today.Sub(createdDay).Hours() / 24)
+	if age <= m.config.StaleAfterDays {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.StaleColor)).Render(fmt.Sprintf(" (%dd)", age))
+}
+
+// priorityMarker renders a priority as one exclamation mark per level, or one arrow per level with
+// symbol indicators (where ! marks overdue tasks)
+func (m *Model) priorityMarker(priority int) string {
+	symbol := "!"
+	if m.config.SymbolIndicators {
+		symbol = "↑"
+	}
+	return lipgloss.NewStyle().Bold(true).Render(strings.Repeat(symbol, priority))
+}
+
+// changePriority raises or lowers the selected task's priority by delta, clamped to the valid range
+func (m *Model) changePriority(delta int) {
+	idx := m.getSelectedItemIndex()
+	if idx < 0 || idx >= len(m.items) {
 		return
 	}
 
-	m.items = items
+	item := m.items[idx]
+	priority := item.Priority + delta
+	if priority < 0 {
+		priority = 0
+	}
+	if priority > database.MaxPriority {
+		priority = database.MaxPriority
+	}
+	if priority == item.Priority {
+		return
+	}
 
-	// Apply grouping and sorting
-	groupedTasks := m.GroupTasks(items)
+	item.Priority = priority
+	if err := database.UpdateTask(m.db, item); err != nil {
+		m.writeFailed(err)
+		return
+	}
+	m.loadTasks()
+	m.restoreSelection(item.ID)
+}
 
-	// Re-populate m.items with the tasks in the order they appear in the table
-	var sortedItems []database.TodoItem
-	for _, group := range groupedTasks {
-		sortedItems = append(sortedItems, group.Tasks...)
+// restoreSelection moves the cursor to the task with the given ID and reports whether it is in view
+func (m *Model) restoreSelection(id int) bool {
+	for idx, item := range m.items {
+		if item.ID == id {
+			m.selectItem(idx)
+			return true
+		}
 	}
-	m.items = sortedItems
+	return false
+}
 
-	tableRows := []table.Row{}
+// statusCell renders the state marker, prefixed with a pin for pinned tasks and a dot for tasks not
+// reviewed yet
+func (m *Model) statusCell(item database.TodoItem) string {
+	marker := stateMarker(item.State)
+	if m.config.SymbolIndicators {
+		marker = m.symbolMarker(item)
+	}
 
-	for _, group := range groupedTasks {
-		// Add group header if grouping is enabled
-		if m.groupBy != database.GroupByNone {
-			groupHeader := fmt.Sprintf("== %s ==", group.GroupName)
-			tableRows = append(tableRows, table.Row{
-				lipgloss.NewStyle().
-					Bold(true).
-					Foreground(lipgloss.Color(m.styles.AccentColor)).
-					Render(groupHeader),
-			})
+	if !item.Reviewed {
+		marker = lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.UnreviewedColor)).Render("•") + marker
+	}
+	if !item.Pinned {
+		return marker
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.AccentColor)).Render("^") + marker
+}
+
+// symbolMarker returns the status marker used with symbol indicators, which also flags overdue tasks
+func (m *Model) symbolMarker(item database.TodoItem) string {
+	switch {
+	case item.State == database.StateDone:
+		return "[✓]"
+	case m.isOverdue(item):
+		return "[!]"
+	default:
+		return stateMarker(item.State)
+	}
+}
+
+// isWaitingContext reports whether context (without @) is the configured waiting context
+func (m *Model) isWaitingContext(context string) bool {
+	return m.config.WaitingContext != "" && strings.EqualFold(context, m.config.WaitingContext)
+}
+
+// hasWaitingContext reports whether the task is tagged with the waiting context
+func (m *Model) hasWaitingContext(item database.TodoItem) bool {
+	for _, context := range item.Contexts {
+		if m.isWaitingContext(context) {
+			return true
 		}
+	}
+	return false
+}
 
-		// Add tasks in the group
-		for _, item := range group.Tasks {
-			status := "[ ]"
-			if item.Status {
-				status = "[x]"
-			}
+// toggleWaitingSearch searches for the waiting context, or clears that search if it is active
+func (m *Model) toggleWaitingSearch() {
+	if m.config.WaitingContext == "" {
+		m.statusMsg = "Set waiting_context to filter waiting tasks"
+		return
+	}
 
-			displayText := item.Description
-			if item.Title != "" {
-				displayText = item.Title
-			}
+	term := "@" + m.config.WaitingContext
+	if m.searchTerm == term {
+		term = ""
+	}
+	m.searchTerm = term
+	m.searchInput.SetValue(term)
+	m.loadTasks()
+}
+
+// followUpMarker flags tasks whose waiting follow-up date has come
+func (m *Model) followUpMarker(item database.TodoItem) string {
+	if !item.NeedsFollowUp(m.today()) {
+		return ""
+	}
+	return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(m.styles.ErrorColor)).Render("[follow up]") + " "
+}
+
+// askWaiting opens the follow-up date prompt for the selected task, suggesting a week from today
+func (m *Model) askWaiting() {
+	idx := m.getSelectedItemIndex()
+	if idx < 0 || idx >= len(m.items) {
+		m.statusMsg = "No task selected"
+		return
+	}
 
-			highlightedText := highlightProjectsAndContexts(displayText, m.styles)
-			combinedText := fmt.Sprintf("%s %s", status, highlightedText)
-			tableRows = append(tableRows, table.Row{combinedText})
+	item := m.items[idx]
+	if item.Status {
+		m.statusMsg = "Only undone tasks can wait"
+		return
+	}
+
+	until := item.WaitingUntil
+	if until.IsZero() {
+		until = m.today().AddDate(0, 0, 7)
+	}
+	m.waitingTaskID = item.ID
+	m.waitingInput.SetValue(until.Format("2006-01-02"))
+	m.waitingInput.CursorEnd()
+	m.waitingInput.Focus()
+	m.mode = WaitingMode
+}
+
+// setWaiting saves the follow-up date entered for the waiting task; an empty date stops waiting
+func (m *Model) setWaiting() {
+	var until time.Time
+	if value := strings.TrimSpace(m.waitingInput.Value()); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			m.statusMsg = "Invalid date, use YYYY-MM-DD"
+			return
 		}
+		until = parsed
+	}
+
+	m.mode = NormalMode
+	m.waitingInput.Blur()
+	if err := database.UpdateTaskWaiting(m.db, m.waitingTaskID, until); err != nil {
+		m.writeFailed(err)
+		return
+	}
+
+	if until.IsZero() {
+		m.statusMsg = "No longer waiting"
+	} else {
+		m.statusMsg = fmt.Sprintf("Waiting until %s", until.Format("2006-01-02"))
+	}
+	m.loadTasks()
+	m.restoreSelection(m.waitingTaskID)
+}
+
+// weekdayLetters maps the letters accepted after the copy-to-weekday key to their weekday
+var weekdayLetters = map[string]time.Weekday{
+	"m": time.Monday,
+	"t": time.Tuesday,
+	"w": time.Wednesday,
+	"r": time.Thursday,
+	"f": time.Friday,
+	"s": time.Saturday,
+	"u": time.Sunday,
+}
+
+// copyToWeekday adds an undone copy of the selected task due on the first given weekday after the
+// viewed date
+func (m *Model) copyToWeekday(weekday time.Weekday) {
+	idx := m.getSelectedItemIndex()
+	if idx < 0 || idx >= len(m.items) {
+		m.statusMsg = "No task selected"
+		return
+	}
+
+	item := m.items[idx]
+	days := (int(weekday)-int(m.viewDate.Weekday())+6)%7 + 1
+	due := m.viewDate.AddDate(0, 0, days)
+
+	task := database.TodoItem{
+		Title:       item.Title,
+		Description: item.Description,
+		DueDate:     due,
+		Projects:    item.Projects,
+		Contexts:    item.Contexts,
+		Priority:    item.Priority,
+	}
+	if err := database.AddTask(m.db, task); err != nil {
+		m.writeFailed(err)
+		return
+	}
+
+	m.loadTasks()
+	m.restoreSelection(item.ID)
+	m.statusMsg = fmt.Sprintf("Copied to %s", due.Format("Mon 2006-01-02"))
+}
+
+// togglePin pins or unpins the selected task, up to the configured number of pins
+func (m *Model) togglePin() {
+	idx := m.getSelectedItemIndex()
+	if idx < 0 || idx >= len(m.items) {
+		m.statusMsg = "No task selected"
+		return
+	}
 
-		// Add empty line between groups
-		if m.groupBy != database.GroupByNone && len(groupedTasks) > 1 {
-			tableRows = append(tableRows, table.Row{""})
+	item := m.items[idx]
+	if !item.Pinned && m.config.MaxPinned > 0 {
+		count, err := database.CountTasks(m.db, "pinned = 1")
+		if err != nil {
+			m.err = err
+			return
+		}
+		if count >= m.config.MaxPinned {
+			m.statusMsg = fmt.Sprintf("Already %d pinned tasks - unpin one first", count)
+			return
 		}
 	}
 
-	m.table.SetRows(tableRows)
+	if err := database.UpdateTaskPinned(m.db, item.ID, !item.Pinned); err != nil {
+		m.writeFailed(err)
+		return
+	}
+	m.loadTasks()
+	m.restoreSelection(item.ID)
+}
+
+// toggleReviewed marks the selected task as reviewed, or as unreviewed again
+func (m *Model) toggleReviewed() {
+	item := m.items[m.getSelectedItemIndex()]
+	if err := database.SetReviewed(m.db, item.ID, !item.Reviewed); err != nil {
+		m.writeFailed(err)
+		return
+	}
+	m.loadTasks()
+	m.restoreSelection(item.ID)
+}
+
+// stateMarker returns the checkbox marker shown for a task state
+func stateMarker(state database.TaskState) string {
+	switch state {
+	case database.StateInProgress:
+		return "[~]"
+	case database.StateDone:
+		return "[x]"
+	default:
+		return "[ ]"
+	}
 }
 
 // For backward compatibility
 func (m *Model) loadTodaysTasks() {
-	m.viewDate = time.Now()
+	m.setViewDate(m.today())
 	m.viewMode = database.TodayViewMode
 	m.loadTasks()
 }
 
+// jumpToTasksBeyond moves the view date to the nearest day with tasks at least the given number of
+// months and days away, honoring the active filter and search. Negative offsets look back.
+func (m *Model) jumpToTasksBeyond(months, days int) {
+	forward := months > 0 || days > 0
+	from := m.viewDate.AddDate(0, months, days).Format("2006-01-02")
+	filter, args := m.viewClause(database.AllViewMode, "")
+
+	date, ok, err := database.NearestTaskDate(m.db, filter, from, forward, args...)
+	if err != nil {
+		m.err = err
+		return
+	}
+	if !ok {
+		if forward {
+			m.statusMsg = "No tasks that far ahead"
+		} else {
+			m.statusMsg = "No tasks that far back"
+		}
+		return
+	}
+
+	m.setViewDate(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, m.viewDate.Location()))
+	m.loadTasks()
+}
+
 // findPrevDayWithTasks finds the previous day that has tasks and updates viewDate
 func (m *Model) findPrevDayWithTasks() {
 	// Start from the day before current viewDate
@@ -106,8 +1488,7 @@ func (m *Model) findPrevDayWithTasks() {
 		dateStr := testDate.Format("2006-01-02")
 
 		// Query the database directly to check if there are tasks for this date
-		query := fmt.Sprintf("SELECT COUNT(*) FROM todos WHERE date(duedate) = date('%s')", dateStr)
-		row := m.db.QueryRow(query)
+		row := m.db.QueryRow("SELECT COUNT(*) FROM todos WHERE deleted_at IS NULL AND date(duedate) = date(?)", dateStr)
 
 		var count int
 		if err := row.Scan(&count); err != nil {
@@ -117,7 +1498,7 @@ func (m *Model) findPrevDayWithTasks() {
 
 		// If we found tasks for this date, update viewDate and load the tasks
 		if count > 0 {
-			m.viewDate = testDate
+			m.setViewDate(testDate)
 			m.loadTasks()
 
 			// Restore original filter
@@ -150,8 +1531,7 @@ func (m *Model) findNextDayWithTasks() {
 		dateStr := testDate.Format("2006-01-02")
 
 		// Query the database directly to check if there are tasks for this date
-		query := fmt.Sprintf("SELECT COUNT(*) FROM todos WHERE date(duedate) = date('%s')", dateStr)
-		row := m.db.QueryRow(query)
+		row := m.db.QueryRow("SELECT COUNT(*) FROM todos WHERE deleted_at IS NULL AND date(duedate) = date(?)", dateStr)
 
 		var count int
 		if err := row.Scan(&count); err != nil {
@@ -161,7 +1541,7 @@ func (m *Model) findNextDayWithTasks() {
 
 		// If we found tasks for this date, update viewDate and load the tasks
 		if count > 0 {
-			m.viewDate = testDate
+			m.setViewDate(testDate)
 			m.loadTasks()
 
 			// Restore original filter
@@ -176,60 +1556,212 @@ func (m *Model) findNextDayWithTasks() {
 	m.loadTasks()
 }
 
+// shareTasks sends the tasks of the current view to the configured share target
+func (m *Model) shareTasks() {
+	if len(m.items) == 0 {
+		m.statusMsg = "Nothing to share"
+		return
+	}
+
+	// The txt export lists descriptions, which are optional in the TUI
+	tasks := make([]database.TodoItem, len(m.items))
+	for i, item := range m.items {
+		if item.Description == "" {
+			item.Description = item.Title
+		}
+		tasks[i] = item
+	}
+	body := commands.FormatTasksTxt(tasks)
+
+	if m.config.ShareTarget == "mailto" {
+		subject := fmt.Sprintf("Tasks for %s", m.viewDate.Format("2006-01-02"))
+		mailto := fmt.Sprintf("mailto:?subject=%s&body=%s", mailtoEscape(subject), mailtoEscape(body))
+		err := utils.OpenURL(mailto)
+		if err == nil {
+			m.statusMsg = fmt.Sprintf("Opened mail client with %d task(s)", len(m.items))
+			return
+		}
+
+		// Fall back to the clipboard when no opener is available
+		utils.Log("Error opening mailto URL: %v", err)
+	}
+
+	if err := utils.CopyToClipboard(body); err != nil {
+		m.err = fmt.Errorf("could not share tasks: %w", err)
+		return
+	}
+	m.statusMsg = fmt.Sprintf("Copied %d task(s) to clipboard", len(m.items))
+}
+
+// copyView copies all tasks in the current view to the clipboard in the given format
+func (m *Model) copyView(format string) {
+	var text string
+	switch format {
+	case "todotxt":
+		text = commands.FormatTasksTodoTxt(m.items)
+	default:
+		text = commands.FormatTasksMarkdown(m.items)
+	}
+
+	if err := utils.CopyToClipboard(text); err != nil {
+		m.err = fmt.Errorf("could not copy tasks: %w", err)
+		return
+	}
+	m.statusMsg = fmt.Sprintf("Copied %d line(s) to clipboard", strings.Count(text, "\n")+1)
+}
+
+// pickRandomTask moves the cursor to a random undone task in the current view
+func (m *Model) pickRandomTask() {
+	var undone []int
+	for idx, item := range m.items {
+		if !item.Status {
+			undone = append(undone, idx)
+		}
+	}
+	if len(undone) == 0 {
+		return
+	}
+
+	idx := undone[m.rng.Intn(len(undone))]
+	m.selectItem(idx)
+	m.statusMsg = fmt.Sprintf("Picked: %s", m.items[idx].Title)
+}
+
+// mailtoEscape encodes text for use in a mailto URL (spaces as %20 rather than +)
+func mailtoEscape(text string) string {
+	return strings.ReplaceAll(url.QueryEscape(text), "+", "%20")
+}
+
+// statusField is the index of the edit form's status field, which follows the text inputs
+const statusField = 3
+
+// formFieldCount returns the number of fields in the form: the edit form also has the status
+func (m *Model) formFieldCount() int {
+	if m.mode == EditMode {
+		return statusField + 1
+	}
+	return statusField
+}
+
 // focusNextInput cycles through the form inputs
 func (m *Model) focusNextInput() {
-	m.activeInput = (m.activeInput + 1) % 3
-	switch m.activeInput {
-	case 0:
-		m.titleInput.Focus()
-		m.descInput.Blur()
-		m.dueDateInput.Blur()
-	case 1:
-		m.titleInput.Blur()
-		m.descInput.Focus()
-		m.dueDateInput.Blur()
-	case 2:
-		m.titleInput.Blur()
-		m.descInput.Blur()
-		m.dueDateInput.Focus()
-	}
+	m.focusInput((m.activeInput + 1) % m.formFieldCount())
 }
 
 // focusPreviousInput cycles through the form inputs
 func (m *Model) focusPreviousInput() {
-	m.activeInput = (m.activeInput - 1) % 3
+	m.focusInput((m.activeInput + m.formFieldCount() - 1) % m.formFieldCount())
+}
 
-	switch m.activeInput {
+// focusInput moves the focus to the given form field
+func (m *Model) focusInput(field int) {
+	m.activeInput = field
+	m.titleInput.Blur()
+	m.descInput.Blur()
+	m.dueDateInput.Blur()
+
+	switch field {
 	case 0:
 		m.titleInput.Focus()
-		m.descInput.Blur()
-		m.dueDateInput.Blur()
 	case 1:
-		m.titleInput.Blur()
 		m.descInput.Focus()
-		m.dueDateInput.Blur()
 	case 2:
-		m.titleInput.Blur()
-		m.descInput.Blur()
 		m.dueDateInput.Focus()
 	}
 }
 
+// moveCalendarSelection moves the selected calendar day by the given number of days, changing month
+// as needed. The calendar view's tasks follow a change of month.
+func (m *Model) moveCalendarSelection(days int) {
+	selected := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month(), m.calendarSelectedDay, 0, 0, 0, 0, m.calendarMonth.Location()).AddDate(0, 0, days)
+	month := time.Date(selected.Year(), selected.Month(), 1, 0, 0, 0, 0, selected.Location())
+	monthChanged := !month.Equal(m.calendarMonth)
+	m.calendarMonth = month
+	m.calendarSelectedDay = selected.Day()
+
+	// The form's date picker borrows the calendar without showing its tasks
+	if monthChanged && m.viewMode == database.CalendarViewMode && !m.pickingDate {
+		m.loadTasks()
+	}
+}
+
+// openDatePicker shows the calendar in the form, starting at the typed due date if it is valid
+func (m *Model) openDatePicker() {
+	m.pickerSavedMonth = m.calendarMonth
+	m.pickerSavedDay = m.calendarSelectedDay
+
+	start := m.viewDate
+	if typed, err := time.Parse("2006-01-02", strings.TrimSpace(m.dueDateInput.Value())); err == nil {
+		start = typed
+	}
+	m.calendarMonth = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, m.viewDate.Location())
+	m.calendarSelectedDay = start.Day()
+	m.pickingDate = true
+}
+
+// updateDatePicker handles keys while the form's date picker is open
+func (m *Model) updateDatePicker(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.keyMap.CalendarLeft):
+		m.moveCalendarSelection(-1)
+	case key.Matches(msg, m.keyMap.CalendarRight):
+		m.moveCalendarSelection(1)
+	case key.Matches(msg, m.keyMap.CalendarUp):
+		m.moveCalendarSelection(-7)
+	case key.Matches(msg, m.keyMap.CalendarDown):
+		m.moveCalendarSelection(7)
+	case key.Matches(msg, m.keyMap.CalendarSelect):
+		picked := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month(), m.calendarSelectedDay, 0, 0, 0, 0, m.calendarMonth.Location())
+		m.dueDateInput.SetValue(picked.Format("2006-01-02"))
+		m.closeDatePicker()
+	case msg.String() == "esc":
+		// Keep the previously typed value
+		m.closeDatePicker()
+	}
+}
+
+// closeDatePicker hides the form's date picker and restores the calendar view state
+func (m *Model) closeDatePicker() {
+	m.calendarMonth = m.pickerSavedMonth
+	m.calendarSelectedDay = m.pickerSavedDay
+	m.pickingDate = false
+}
+
 // submitForm processes the form data based on the current mode
 func (m *Model) submitForm() {
 	title := strings.TrimSpace(m.titleInput.Value())
 	desc := strings.TrimSpace(m.descInput.Value())
 	dueDate := strings.TrimSpace(m.dueDateInput.Value())
 
+	// Keep new tasks visible in a project/context filtered view by tagging them
+	if m.mode == AddMode {
+		if tag := m.inheritedTag(); tag != "" && !hasTag(title+" "+desc, tag) {
+			title = strings.TrimSpace(title + " " + tag)
+		}
+	}
+
+	// Keep the form open on an overlong title so it can be shortened
+	title, truncated, err := commands.FitTitle(m.config, title)
+	if err != nil {
+		m.statusMsg = "Title not saved: " + err.Error()
+		return
+	}
+
 	// Parse projects and contexts from title and description
 	projects := parseProjects(title)
 	projects = append(projects, parseProjects(desc)...)
 	contexts := parseContexts(title)
 	contexts = append(contexts, parseContexts(desc)...)
 
+	// The same tag may appear in both title and description
+	projects = database.DedupeTags(projects, m.config.TagsCaseSensitive)
+	contexts = database.DedupeTags(contexts, m.config.TagsCaseSensitive)
+	if m.mode == AddMode {
+		contexts = commands.WithProjectDefaultContexts(m.config, projects, contexts)
+	}
+
 	// Parse due date
 	var parsedDueDate time.Time
-	var err error
 	if dueDate != "" {
 		parsedDueDate, err = time.Parse("2006-01-02", dueDate)
 		if err != nil {
@@ -253,13 +1785,45 @@ func (m *Model) submitForm() {
 			Contexts:    contexts,
 		}
 
+		// Warn once about an identical undone task; submitting again adds it anyway
+		if m.config.WarnDuplicates {
+			dupKey := "duplicate|" + title + "|" + parsedDueDate.Format("2006-01-02")
+			dup, err := database.FindDuplicate(m.db, title, parsedDueDate)
+			if err != nil {
+				m.err = err
+				return
+			}
+			if dup != nil && m.submitWarned != dupKey {
+				m.submitWarned = dupKey
+				m.statusMsg = fmt.Sprintf("Task %d is already due that day - submit again to add anyway", dup.ID)
+				return
+			}
+		}
+
+		// Catch typos like a past year; "confirm" asks to submit again, "note" only mentions it
+		pastNote := ""
+		if m.isPastDate(parsedDueDate) {
+			switch m.config.WarnPastDueDate {
+			case "confirm":
+				pastKey := "past|" + title + "|" + parsedDueDate.Format("2006-01-02")
+				if m.submitWarned != pastKey {
+					m.submitWarned = pastKey
+					m.statusMsg = fmt.Sprintf("%s is in the past - submit again to add anyway", parsedDueDate.Format("2006-01-02"))
+					return
+				}
+			case "note":
+				pastNote = fmt.Sprintf("Note: added with a past due date (%s)", parsedDueDate.Format("2006-01-02"))
+			}
+		}
+
 		// Insert new task using the database function
-		err := database.AddTask(m.db, task)
-		if err != nil {
-			m.err = err
-		} else {
-			m.loadTasks()
+		// Keep the form open on failure so the task can be saved again
+		if err := database.AddTask(m.db, task); err != nil {
+			m.writeFailed(err)
+			return
 		}
+		m.loadTasks()
+		m.statusMsg = pastNote
 
 	case EditMode:
 		if m.editingItem != nil {
@@ -269,23 +1833,50 @@ func (m *Model) submitForm() {
 			m.editingItem.DueDate = parsedDueDate
 			m.editingItem.Projects = projects
 			m.editingItem.Contexts = contexts
+			m.editingItem.SetState(m.formState)
 
 			// Update using the database function
-			err := database.UpdateTask(m.db, *m.editingItem)
-			if err != nil {
-				m.err = err
-			} else {
-				m.loadTasks()
+			if err := database.UpdateTask(m.db, *m.editingItem); err != nil {
+				m.writeFailed(err)
+				return
 			}
+			m.loadTasks()
 		}
 	}
 
+	if truncated {
+		m.statusMsg = strings.TrimSpace(fmt.Sprintf("Title truncated to %d characters. %s", m.config.MaxTitleLength, m.statusMsg))
+	}
+
 	// Reset state
 	m.mode = NormalMode
 	m.resetInputs()
 	m.editingItem = nil
 }
 
+// inheritedTag returns the +project or @context tag new tasks inherit from the active search, if enabled
+func (m *Model) inheritedTag() string {
+	if !m.config.InheritViewFilterOnAdd {
+		return ""
+	}
+
+	term := strings.TrimSpace(m.searchTerm)
+	if len(term) > 1 && (term[0] == '+' || term[0] == '@') && !strings.ContainsAny(term, " \t") {
+		return term
+	}
+	return ""
+}
+
+// hasTag reports whether text already contains the given tag as a word (case-insensitively)
+func hasTag(text, tag string) bool {
+	for _, word := range strings.Fields(text) {
+		if strings.EqualFold(word, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 // parseProjects extracts all project tags (prefixed with +) from the description
 func parseProjects(description string) []string {
 	var projects []string
@@ -316,30 +1907,77 @@ func parseContexts(description string) []string {
 	return contexts
 }
 
-// highlightProjectsAndContexts highlights project and context tags in text
-func highlightProjectsAndContexts(text string, styles config.Styles) string {
+// textSearchTerm returns the search term when it is plain text, or "" for +project and @context searches
+// which match whole tags rather than a substring
+func (m *Model) textSearchTerm() string {
+	_, term := database.ParseStatusQualifier(m.searchTerm)
+	if strings.HasPrefix(term, "+") || strings.HasPrefix(term, "@") {
+		return ""
+	}
+	return term
+}
+
+// highlightProjectsAndContexts highlights project and context tags in text (the waiting context in
+// its own color), and any matches of a plain text search in the words between them
+func (m *Model) highlightProjectsAndContexts(text string) string {
+	searchTerm, styles := m.textSearchTerm(), m.styles
+
 	// Split the text into words
 	words := strings.Fields(text)
 	var result strings.Builder
 
+	// Plain words are collected into runs so a search term can match across them
+	var run []string
+	flushRun := func() {
+		if len(run) > 0 {
+			if result.Len() > 0 {
+				result.WriteString(" ") // Add space between words
+			}
+			result.WriteString(highlightSearchMatches(strings.Join(run, " "), searchTerm, styles))
+			run = nil
+		}
+	}
+
 	// Process each word
-	for i, word := range words {
-		if i > 0 {
+	for _, word := range words {
+		isTag := len(word) > 1 && (strings.HasPrefix(word, "+") || strings.HasPrefix(word, "@"))
+		if !isTag {
+			// Regular word, highlighted with its run
+			run = append(run, word)
+			continue
+		}
+
+		flushRun()
+		if result.Len() > 0 {
 			result.WriteString(" ") // Add space between words
 		}
 
-		// Check if word is a project tag (+project)
-		if strings.HasPrefix(word, "+") && len(word) > 1 {
+		if strings.HasPrefix(word, "+") {
 			// Highlight project with a different color (green)
 			result.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ProjectColor)).Render(word))
-		} else if strings.HasPrefix(word, "@") && len(word) > 1 {
+		} else if m.isWaitingContext(word[1:]) {
+			// Delegated tasks stand apart from other contexts
+			result.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(styles.WaitingColor)).Render(word))
+		} else {
 			// Highlight context with a different color (blue)
 			result.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ContextColor)).Render(word))
-		} else {
-			// Regular word, no highlighting
-			result.WriteString(word)
 		}
 	}
+	flushRun()
 
 	return result.String()
 }
+
+// highlightSearchMatches marks every case-insensitive occurrence of term in text, like the SQL LIKE search
+func highlightSearchMatches(text string, term string, styles config.Styles) string {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return text
+	}
+
+	style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(styles.SearchHighlightColor))
+	re := regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+	return re.ReplaceAllStringFunc(text, func(match string) string {
+		return style.Render(match)
+	})
+}
@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"awp/pkg/config"
+	"awp/pkg/database"
+	"awp/pkg/utils"
+)
+
+// newTestModel returns a model with the default config on an in-memory database holding tasks
+func newTestModel(t *testing.T, tasks ...database.TodoItem) (*Model, *sql.DB) {
+	t.Helper()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"styles_file": "`+filepath.Join(dir, "styles.json")+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, styles, err := config.Load(configPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := database.OpenMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, task := range tasks {
+		if err := database.AddTask(db, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewModel(db, cfg, styles)
+	m.update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	return &m, db
+}
+
+// setClock fixes the current time, in UTC, for the rest of the test
+func setClock(t *testing.T, now time.Time) {
+	t.Helper()
+
+	prevNow, prevLocation := utils.Now, utils.Location()
+	utils.Now = func() time.Time { return now }
+	utils.SetLocation(time.UTC)
+	t.Cleanup(func() {
+		utils.Now = prevNow
+		utils.SetLocation(prevLocation)
+	})
+}
+
+// update passes msg to the model like the bubbletea runtime does
+func (m *Model) update(msg tea.Msg) {
+	next, _ := m.Update(msg)
+	*m = next.(Model)
+}
+
+// keyPress returns the message for typing s
+func keyPress(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// itemTitles returns the titles of the loaded tasks, sorted
+func itemTitles(m *Model) []string {
+	result := []string{}
+	for _, item := range m.items {
+		result = append(result, item.Title)
+	}
+	slices.Sort(result)
+	return result
+}
+
+// itemStates returns the title and state of each task, sorted, to compare the list with the database
+func itemStates(tasks []database.TodoItem) []string {
+	var states []string
+	for _, task := range tasks {
+		states = append(states, fmt.Sprintf("%s:%d", task.Title, task.State))
+	}
+	slices.Sort(states)
+	return states
+}
+
+func TestFailedWriteResyncs(t *testing.T) {
+	m, db := newTestModel(t,
+		database.TodoItem{Title: "first", DueDate: utils.Today(0)},
+		database.TodoItem{Title: "second", DueDate: utils.Today(0)},
+	)
+	if len(m.items) != 2 {
+		t.Fatalf("loaded %d tasks, want 2", len(m.items))
+	}
+
+	// Any write fails from here on
+	if _, err := db.Exec("PRAGMA query_only = ON"); err != nil {
+		t.Fatal(err)
+	}
+
+	m.update(keyPress("x"))
+	if m.err == nil {
+		t.Fatal("toggling the status reported no error")
+	}
+	stored, err := database.LoadTasks(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := itemStates(m.items), itemStates(stored); !slices.Equal(got, want) {
+		t.Errorf("after a failed toggle the list shows %q, the database holds %q", got, want)
+	}
+
+	// The edit form changes the task in the list before it is saved
+	m.err = nil
+	m.update(keyPress("e"))
+	if m.mode != EditMode {
+		t.Fatalf("mode = %v, want the edit form", m.mode)
+	}
+	m.titleInput.SetValue("renamed")
+	m.submitForm()
+	if m.err == nil {
+		t.Fatal("saving the edit reported no error")
+	}
+	if got, want := itemStates(m.items), itemStates(stored); !slices.Equal(got, want) {
+		t.Errorf("after a failed edit the list shows %q, the database holds %q", got, want)
+	}
+}
+
+func TestCalendarToListTransition(t *testing.T) {
+	setClock(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC))
+	due := func(day string) time.Time {
+		d, _ := time.Parse("2006-01-02", day)
+		return d
+	}
+	m, _ := newTestModel(t,
+		database.TodoItem{Title: "2026-10-03", DueDate: due("2026-10-03")},
+		database.TodoItem{Title: "2026-10-17", DueDate: due("2026-10-17")},
+		database.TodoItem{Title: "2026-11-02", DueDate: due("2026-11-02")},
+	)
+	toggleCalendar := tea.KeyMsg{Type: tea.KeyCtrlC}
+
+	steps := []struct {
+		name string
+		msgs []tea.Msg
+		want []string
+	}{
+		{"list of today", nil, []string{"2026-10-17"}},
+		{"calendar shows the month", []tea.Msg{toggleCalendar}, []string{"2026-10-03", "2026-10-17"}},
+		{"calendar moved to the next month", []tea.Msg{tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown}}, []string{"2026-11-02"}},
+		{"back to the list of today", []tea.Msg{toggleCalendar}, []string{"2026-10-17"}},
+		{"calendar keeps the month", []tea.Msg{toggleCalendar}, []string{"2026-11-02"}},
+		{"list of the selected day", []tea.Msg{
+			tea.KeyMsg{Type: tea.KeyLeft}, tea.KeyMsg{Type: tea.KeyLeft}, tea.KeyMsg{Type: tea.KeyLeft},
+			tea.KeyMsg{Type: tea.KeyLeft}, tea.KeyMsg{Type: tea.KeyLeft}, tea.KeyMsg{Type: tea.KeyEnter},
+		}, []string{"2026-11-02"}},
+	}
+
+	for _, step := range steps {
+		for _, msg := range step.msgs {
+			m.update(msg)
+		}
+		if got := itemTitles(m); !slices.Equal(got, step.want) {
+			t.Errorf("%s: items = %q, want %q", step.name, got, step.want)
+		}
+	}
+	if m.viewMode != database.TodayViewMode {
+		t.Errorf("view mode = %v, want the day list", m.viewMode)
+	}
+}
+
+func TestSortOrderFlip(t *testing.T) {
+	today := utils.Today(0)
+	m, _ := newTestModel(t,
+		database.TodoItem{Title: "beta", DueDate: today, Projects: []string{"work"}},
+		database.TodoItem{Title: "alpha", DueDate: today, Projects: []string{"home"}},
+		database.TodoItem{Title: "beta", DueDate: today, Projects: []string{"work"}},
+		database.TodoItem{Title: "Beta", DueDate: today},
+	)
+	ids := func() []int {
+		result := []int{}
+		for _, item := range m.items {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	// Title sorts in SQL, project in Go; both must flip exactly, ties included
+	for _, sortBy := range []database.SortBy{database.SortByTitle, database.SortByProject} {
+		m.sortBy = sortBy
+		m.sortOrder = database.SortAsc
+		m.loadTasks()
+		before := ids()
+
+		m.update(keyPress("o"))
+		after := ids()
+		slices.Reverse(after)
+		if !slices.Equal(before, after) {
+			t.Errorf("sort by %s: flipping %v gave %v", sortByNames[sortBy], before, ids())
+		}
+	}
+}
+
+// runFromPalette opens the command palette, types query and runs the first action it offers
+func (m *Model) runFromPalette(t *testing.T, query, want string) {
+	t.Helper()
+
+	m.update(keyPress(":"))
+	m.paletteInput.SetValue(query)
+	if actions := m.paletteActions(); len(actions) == 0 || actions[0] != want {
+		t.Fatalf("palette offers %q for %q, want %s first", actions, query, want)
+	}
+	m.update(tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestPaletteRunsNamedAction(t *testing.T) {
+	today := utils.Today(0)
+	m, _ := newTestModel(t,
+		database.TodoItem{Title: "open", DueDate: today},
+		database.TodoItem{Title: "closed", DueDate: today, Status: true},
+	)
+
+	// Shares its key with the form's date picker
+	m.runFromPalette(t, "show only done tasks", "ShowDoneTasks")
+	if m.taskFilter != database.DoneTasksFilter || !slices.Equal(itemTitles(m), []string{"closed"}) {
+		t.Errorf("after ShowDoneTasks the filter is %v showing %q", m.taskFilter, itemTitles(m))
+	}
+
+	m.runFromPalette(t, "toggle sort order", "ToggleSortOrder")
+	if m.sortOrder != database.SortDesc {
+		t.Errorf("after ToggleSortOrder the order is %v", m.sortOrder)
+	}
+
+	// Form and calendar actions are only offered where their keys run them
+	m.paletteInput.SetValue("")
+	listActions := m.paletteActions()
+	for _, action := range []string{"PickDate", "SaveForm", "CalendarSelect", "CalendarLayout", "CalendarLeft"} {
+		if slices.Contains(listActions, action) {
+			t.Errorf("the task list palette offers %s", action)
+		}
+	}
+	if !slices.Contains(listActions, "ZoomGroup") {
+		t.Error("the task list palette does not offer ZoomGroup")
+	}
+
+	m.runFromPalette(t, "toggle calendar view", "ToggleCalendarView")
+	if m.viewMode != database.CalendarViewMode {
+		t.Fatalf("after ToggleCalendarView the view mode is %v", m.viewMode)
+	}
+	m.paletteInput.SetValue("")
+	calendarActions := m.paletteActions()
+	if slices.Contains(calendarActions, "ZoomGroup") || slices.Contains(calendarActions, "PickDate") {
+		t.Errorf("the calendar palette offers %q", calendarActions)
+	}
+
+	m.runFromPalette(t, "switch the calendar between month and week", "CalendarLayout")
+	if m.calendarLayout != WeekLayout {
+		t.Errorf("after CalendarLayout the layout is %v", m.calendarLayout)
+	}
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"time"
+
+	"awp/pkg/database"
+)
+
+// maxDateHistory bounds the number of remembered view dates
+const maxDateHistory = 50
+
+// setViewDate changes the viewed date and records it in the navigation history
+func (m *Model) setViewDate(date time.Time) {
+	m.viewDate = date
+	m.pushDateHistory(date)
+}
+
+// pushDateHistory records a visited date, dropping any forward history like a browser
+func (m *Model) pushDateHistory(date time.Time) {
+	// Don't record the same day twice in a row
+	if len(m.dateHistory) > 0 && sameDay(m.dateHistory[m.historyPos], date) {
+		return
+	}
+
+	if len(m.dateHistory) > 0 {
+		m.dateHistory = m.dateHistory[:m.historyPos+1]
+	}
+	m.dateHistory = append(m.dateHistory, date)
+
+	if len(m.dateHistory) > maxDateHistory {
+		m.dateHistory = m.dateHistory[len(m.dateHistory)-maxDateHistory:]
+	}
+	m.historyPos = len(m.dateHistory) - 1
+}
+
+// historyBack moves to the previously visited date, if any
+func (m *Model) historyBack() {
+	if m.historyPos <= 0 {
+		return
+	}
+	m.historyPos--
+	m.showHistoryDate()
+}
+
+// historyForward moves to the next visited date after going back, if any
+func (m *Model) historyForward() {
+	if m.historyPos >= len(m.dateHistory)-1 {
+		return
+	}
+	m.historyPos++
+	m.showHistoryDate()
+}
+
+// showHistoryDate displays the date at the current history position without recording it again
+func (m *Model) showHistoryDate() {
+	m.viewDate = m.dateHistory[m.historyPos]
+	m.viewMode = database.TodayViewMode
+	m.loadTasks()
+}
+
+// sameDay reports whether two times fall on the same calendar day
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
@@ -2,6 +2,8 @@ package ui
 
 import (
 	"database/sql"
+	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/table"
@@ -12,6 +14,7 @@ import (
 	"awp/pkg/config"
 	"awp/pkg/database"
 	"awp/pkg/keymaps"
+	"awp/pkg/utils"
 )
 
 // InputMode represents the current input mode
@@ -22,18 +25,47 @@ const (
 	AddMode
 	EditMode
 	DeleteConfirmMode
-	SearchMode   // Mode for searching tasks
-	HelpViewMode // Mode for displaying help
+	SearchMode       // Mode for searching tasks
+	HelpViewMode     // Mode for displaying help
+	TemplateMode     // Mode for picking a task template
+	SortMenuMode     // Mode for choosing the sort field from a menu
+	GroupFilterMode  // Mode for filtering groups by name
+	ExportMenuMode   // Mode for choosing an export format
+	WaitingMode      // Mode for entering the follow-up date of a waiting task
+	GotoIDMode       // Mode for entering the ID of a task to jump to
+	DatabaseMenuMode // Mode for choosing the task list (database) to switch to
+	TrashMode        // Mode for browsing deleted tasks and restoring them
+	PaletteMode      // Mode for finding an action by name and running it
 )
 
+// CalendarLayout is how much of the calendar is shown at once
+type CalendarLayout int
+
+const (
+	MonthLayout CalendarLayout = iota // The whole month as a grid of days
+	WeekLayout                        // The week of the selected day with its tasks
+)
+
+// savedView holds view state that can be restored later
+type savedView struct {
+	viewMode   database.ViewMode
+	taskFilter database.TaskFilter
+	viewDate   time.Time
+	searchTerm string
+	groupBy    database.GroupBy
+}
+
 // Model represents the application state
 type Model struct {
 	table         table.Model
 	items         []database.TodoItem
+	rowItems      []int          // Index into items for each table row, -1 for headers and spacers
+	rowGroups     map[int]string // Group name for each group header row
 	db            *sql.DB
 	showCommands  bool
 	width, height int
 	err           error
+	statusMsg     string // One-off note shown in the status line until the next key press
 
 	// Configuration
 	config config.Config
@@ -46,17 +78,105 @@ type Model struct {
 	viewDate   time.Time
 	searchTerm string
 
+	// searchMatches is how many tasks the search matched when that is more than it loaded (0 otherwise)
+	searchMatches int
+
+	// Browser-style history of visited view dates
+	dateHistory []time.Time
+	historyPos  int
+
 	// Form state
-	mode         InputMode
-	titleInput   textinput.Model
-	descInput    textinput.Model
-	dueDateInput textinput.Model
-	searchInput  textinput.Model
-	activeInput  int
+	mode             InputMode
+	titleInput       textinput.Model
+	descInput        textinput.Model
+	dueDateInput     textinput.Model
+	searchInput      textinput.Model
+	groupFilterInput textinput.Model
+	activeInput      int
+	formState        database.TaskState // Status chosen in the edit form
+
+	// Follow-up date input and the task being marked as waiting
+	waitingInput  textinput.Model
+	waitingTaskID int
+
+	// Task ID input for jumping to a task
+	gotoInput textinput.Model
 
 	// Edit/delete state
 	editingItem *database.TodoItem
 
+	// Template picker state
+	templateCursor int
+
+	// Trash view state
+	trashItems  []database.TodoItem
+	trashCursor int
+
+	// Command palette state
+	paletteInput  textinput.Model
+	paletteCursor int
+
+	// Waiting for the format key after the copy-view key
+	pendingCopy bool
+
+	// Waiting for the weekday letter after the copy-to-weekday key
+	pendingWeekday bool
+
+	// Export menu scope: all tasks instead of the current view
+	exportAllTasks bool
+
+	// Waiting for y/n before loading a large all-tasks view
+	pendingAllView bool
+
+	// Waiting for y/n before moving this many overdue tasks
+	pendingDefer int
+
+	// Last warning shown on submit (kind, title and date); submitting the same task again proceeds
+	submitWarned string
+
+	// Vim-style numeric prefix typed before a motion (e.g. 5j)
+	countPrefix string
+
+	// Only show groups whose name contains this text (case-insensitive)
+	groupFilter string
+
+	// Startup banner (overdue tasks, unusable styles file), cleared by the first key press
+	banner string
+
+	// Show descriptions instead of titles as the primary row text
+	showDescriptions bool
+
+	// Prefix rows with the task's ID, to use it with CLI commands
+	showIDs bool
+
+	// Calendar month or week layout
+	calendarLayout CalendarLayout
+
+	// Rows added to or taken from the table's default height
+	tableHeightAdjust int
+
+	// Show the next 7 days' task counts beside the table
+	showWeekSidebar bool
+
+	// Temporary files written to open the view, removed by Cleanup
+	openedViewFiles []string
+
+	// Session switch to UTC; homeLocation is the configured zone to switch back to
+	useUTC       bool
+	homeLocation *time.Location
+
+	// Project the view is pinned to across day and view mode changes ("" for none)
+	focusProject string
+
+	// Projects toggled on in the chip bar (a task matches any of them), the chips on offer and
+	// whether the bar takes the number keys
+	chipProjects []string
+	chipNames    []string
+	chipBarOpen  bool
+
+	// Random source for picking a task
+	rng *rand.Rand
+
 	// Sorting and grouping state
 	sortBy    database.SortBy
 	groupBy   database.GroupBy
@@ -64,14 +184,24 @@ type Model struct {
 
 	calendarMonth       time.Time
 	calendarSelectedDay int // Selected day in calendar view (1-31)
+
+	// Date picker opened from the form's due date field; it borrows the calendar
+	// state and restores it when closed
+	pickingDate      bool
+	pickerSavedMonth time.Time
+	pickerSavedDay   int
+
+	// View to return to after zooming into a project group
+	zoomPrev *savedView
+
+	// Remembered cursor positions (task IDs) per view signature
+	viewKey      string
+	cursorMemory map[string]int
 }
 
 // NewModel creates a new UI model with the provided configuration
 func NewModel(db *sql.DB, cfg config.Config, styles config.Styles) Model {
-	// Create an empty column - the title will be empty to avoid showing a header
-	columns := []table.Column{
-		{Title: "", Width: 60},
-	}
+	columns := buildColumns(cfg.Columns)
 
 	t := table.New(
 		table.WithColumns(columns),
@@ -108,7 +238,8 @@ func NewModel(db *sql.DB, cfg config.Config, styles config.Styles) Model {
 	dueDateInput := textinput.New()
 	dueDateInput.Placeholder = "Due Date (YYYY-MM-DD, optional)"
 	dueDateInput.Width = 40
-	dueDateInput.SetValue(time.Now().Format("2006-01-02"))
+	today := utils.Today(cfg.DayCutoffHour)
+	dueDateInput.SetValue(today.Format("2006-01-02"))
 
 	// Initialize search input
 	searchInput := textinput.New()
@@ -116,6 +247,27 @@ func NewModel(db *sql.DB, cfg config.Config, styles config.Styles) Model {
 	searchInput.Focus()
 	searchInput.Width = 40
 
+	// Initialize group filter input
+	groupFilterInput := textinput.New()
+	groupFilterInput.Placeholder = "Group name"
+	groupFilterInput.Width = 40
+
+	// Initialize waiting follow-up date input
+	waitingInput := textinput.New()
+	waitingInput.Placeholder = "Follow-up date (YYYY-MM-DD, empty to stop waiting)"
+	waitingInput.Width = 40
+
+	// Initialize task ID input
+	gotoInput := textinput.New()
+	gotoInput.Placeholder = "Task ID"
+	gotoInput.CharLimit = 10
+	gotoInput.Width = 20
+
+	// Initialize command palette input
+	paletteInput := textinput.New()
+	paletteInput.Placeholder = "Type to find an action"
+	paletteInput.Width = 40
+
 	m := Model{
 		table:               t,
 		db:                  db,
@@ -128,21 +280,51 @@ func NewModel(db *sql.DB, cfg config.Config, styles config.Styles) Model {
 		descInput:           descInput,
 		dueDateInput:        dueDateInput,
 		searchInput:         searchInput,
+		groupFilterInput:    groupFilterInput,
+		waitingInput:        waitingInput,
+		gotoInput:           gotoInput,
+		paletteInput:        paletteInput,
 		activeInput:         0,
 		viewMode:            database.TodayViewMode,  // Default view mode shows today's tasks
 		taskFilter:          database.AllTasksFilter, // Default to showing all tasks (both done and undone)
-		viewDate:            time.Now(),
+		viewDate:            today,
 		searchTerm:          "", // Initialize empty search term
-		calendarMonth:       time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Now().Location()),
-		calendarSelectedDay: time.Now().Day(), // Initialize to today's day
+		calendarMonth:       time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location()),
+		calendarSelectedDay: today.Day(), // Initialize to today's day
+		cursorMemory:        make(map[string]int),
+		homeLocation:        utils.Location(),
+		showWeekSidebar:     cfg.ShowWeekSidebar,
+		tableHeightAdjust:   cfg.TableHeightAdjust,
+		rng:                 rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 
 	// Load initial data
 	m.loadTodaysTasks()
 
+	if cfg.AlertOnOverdue {
+		m.alertOverdue()
+	}
+
 	return m
 }
 
+// buildColumns returns the table columns for the configured field names
+func buildColumns(fields []string) []table.Column {
+	if len(fields) == 0 {
+		// A single untitled column holding the combined row text, so no header shows
+		return []table.Column{
+			{Title: "", Width: 60},
+		}
+	}
+
+	// config.Load only accepts the names in config.ColumnWidths
+	var columns []table.Column
+	for _, field := range fields {
+		columns = append(columns, table.Column{Title: strings.ToUpper(field[:1]) + field[1:], Width: config.ColumnWidths[field]})
+	}
+	return columns
+}
+
 // Init initializes the model (required by Bubble Tea Model interface)
 func (m Model) Init() tea.Cmd {
 	return nil
@@ -155,6 +337,7 @@ func (m *Model) resetInputs() {
 	m.dueDateInput.SetValue(m.viewDate.Format("2006-01-02"))
 
 	m.activeInput = 0
+	m.submitWarned = ""
 	m.titleInput.Focus()
 	m.descInput.Blur()
 	m.dueDateInput.Blur()
@@ -8,50 +8,64 @@ import (
 	"awp/pkg/database"
 )
 
+// sortByNames holds the display name of each SortBy value
+var sortByNames = []string{"title", "description", "due date", "project", "context", "created", "status", "priority"}
+
+// groupByNames holds the display name of each GroupBy value
+var groupByNames = []string{"", "project", "context", "daily", "weekly", "monthly", "yearly", "status"}
+
 // GroupedTasks represents tasks grouped by a common attribute
 type GroupedTasks struct {
 	GroupName string
 	Tasks     []database.TodoItem
 }
 
-// SortTasks sorts tasks based on the specified criteria
+// SortTasks sorts tasks based on the specified criteria. Ties are broken by ID in the same
+// direction, matching database.OrderByClause, so the order does not depend on how the tasks were
+// loaded and flipping the sort order reverses the list exactly.
 func (m *Model) SortTasks(tasks []database.TodoItem) []database.TodoItem {
 	sortedTasks := make([]database.TodoItem, len(tasks))
 	copy(sortedTasks, tasks)
 
 	sort.Slice(sortedTasks, func(i, j int) bool {
-		var result bool
-
-		switch m.sortBy {
-		case database.SortByTitle:
-			result = strings.ToLower(sortedTasks[i].Title) < strings.ToLower(sortedTasks[j].Title)
-		case database.SortByDescription:
-			result = strings.ToLower(sortedTasks[i].Description) < strings.ToLower(sortedTasks[j].Description)
-		case database.SortByDueDate:
-			result = sortedTasks[i].DueDate.Before(sortedTasks[j].DueDate)
-		case database.SortByCreated:
-			result = sortedTasks[i].Created.Before(sortedTasks[j].Created)
-		case database.SortByStatus:
-			result = !sortedTasks[i].Status && sortedTasks[j].Status // Undone first
-		case database.SortByProject:
-			proj1 := getFirstProject(sortedTasks[i])
-			proj2 := getFirstProject(sortedTasks[j])
-			result = strings.ToLower(proj1) < strings.ToLower(proj2)
-		case database.SortByContext:
-			ctx1 := getFirstContext(sortedTasks[i])
-			ctx2 := getFirstContext(sortedTasks[j])
-			result = strings.ToLower(ctx1) < strings.ToLower(ctx2)
-		}
-
 		if m.sortOrder == database.SortDesc {
-			result = !result
+			return m.taskLess(sortedTasks[j], sortedTasks[i])
 		}
-		return result
+		return m.taskLess(sortedTasks[i], sortedTasks[j])
 	})
 
 	return sortedTasks
 }
 
+// taskLess reports whether a sorts before b in ascending order of the sort field, then ID
+func (m *Model) taskLess(a, b database.TodoItem) bool {
+	var cmp int
+
+	switch m.sortBy {
+	case database.SortByTitle:
+		cmp = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	case database.SortByDescription:
+		cmp = strings.Compare(strings.ToLower(a.Description), strings.ToLower(b.Description))
+	case database.SortByDueDate:
+		cmp = a.DueDate.Compare(b.DueDate)
+	case database.SortByCreated:
+		cmp = a.Created.Compare(b.Created)
+	case database.SortByStatus:
+		cmp = int(a.State - b.State) // To do, then in progress, then done
+	case database.SortByPriority:
+		cmp = b.Priority - a.Priority // Highest priority first
+	case database.SortByProject:
+		cmp = strings.Compare(strings.ToLower(getFirstProject(a)), strings.ToLower(getFirstProject(b)))
+	case database.SortByContext:
+		cmp = strings.Compare(strings.ToLower(getFirstContext(a)), strings.ToLower(getFirstContext(b)))
+	}
+
+	if cmp != 0 {
+		return cmp < 0
+	}
+	return a.ID < b.ID
+}
+
 // GroupTasks groups tasks based on the specified criteria
 func (m *Model) GroupTasks(tasks []database.TodoItem) []GroupedTasks {
 	if m.groupBy == database.GroupByNone {
@@ -65,7 +79,8 @@ func (m *Model) GroupTasks(tasks []database.TodoItem) []GroupedTasks {
 
 		switch m.groupBy {
 		case database.GroupByProject:
-			groupKey = getFirstProject(task)
+			// Group hierarchical projects (+work/clientA) by their top-level area
+			groupKey, _, _ = strings.Cut(getFirstProject(task), "/")
 			if groupKey == "" {
 				groupKey = "No Project"
 			} else {
@@ -92,6 +107,14 @@ func (m *Model) GroupTasks(tasks []database.TodoItem) []GroupedTasks {
 
 		case database.GroupByDueDateYearly:
 			groupKey = task.DueDate.Format("2006")
+
+		case database.GroupByStatus:
+			groupKey = stateName(task.State)
+		}
+
+		// Skip groups hidden by the group filter
+		if m.groupFilter != "" && !strings.Contains(strings.ToLower(groupKey), strings.ToLower(m.groupFilter)) {
+			continue
 		}
 
 		groups[groupKey] = append(groups[groupKey], task)
@@ -105,16 +128,58 @@ func (m *Model) GroupTasks(tasks []database.TodoItem) []GroupedTasks {
 	}
 	sort.Strings(groupNames)
 
+	other := collapseSmallGroups(groups, groupNames, m.config.MaxGroups)
+
 	for _, name := range groupNames {
+		if _, ok := groups[name]; !ok {
+			continue // Collapsed into "Other"
+		}
 		result = append(result, GroupedTasks{
 			GroupName: name,
 			Tasks:     m.SortTasks(groups[name]),
 		})
 	}
 
+	if len(other) > 0 {
+		result = append(result, GroupedTasks{
+			GroupName: otherGroupName,
+			Tasks:     m.SortTasks(other),
+		})
+	}
+
 	return result
 }
 
+// otherGroupName is the group holding the tasks of collapsed groups
+const otherGroupName = "Other"
+
+// collapseSmallGroups removes the smallest groups from groups when there are more than maxGroups,
+// returning their tasks so they can be shown together as one "Other" group
+func collapseSmallGroups(groups map[string][]database.TodoItem, names []string, maxGroups int) []database.TodoItem {
+	if maxGroups <= 0 || len(names) <= maxGroups {
+		return nil
+	}
+
+	// Largest groups first; names are already sorted so ties stay alphabetical
+	bySize := append([]string(nil), names...)
+	sort.SliceStable(bySize, func(i, j int) bool {
+		return len(groups[bySize[i]]) > len(groups[bySize[j]])
+	})
+
+	// Keep room for the "Other" group itself
+	keep := maxGroups - 1
+	if keep < 1 {
+		keep = 1
+	}
+
+	var other []database.TodoItem
+	for _, name := range bySize[keep:] {
+		other = append(other, groups[name]...)
+		delete(groups, name)
+	}
+	return other
+}
+
 // Helper functions
 func getFirstProject(task database.TodoItem) string {
 	if len(task.Projects) > 0 {
@@ -123,6 +188,17 @@ func getFirstProject(task database.TodoItem) string {
 	return ""
 }
 
+func stateName(state database.TaskState) string {
+	switch state {
+	case database.StateInProgress:
+		return "In Progress"
+	case database.StateDone:
+		return "Done"
+	default:
+		return "To Do"
+	}
+}
+
 func getFirstContext(task database.TodoItem) string {
 	if len(task.Contexts) > 0 {
 		return task.Contexts[0]
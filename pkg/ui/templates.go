@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+)
+
+// templateNames returns the configured template names in display order
+func (m *Model) templateNames() []string {
+	var names []string
+	for name := range m.config.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyTemplate opens the add form pre-filled from the named template
+func (m *Model) applyTemplate(name string) {
+	tmpl, ok := m.config.Templates[name]
+	if !ok {
+		return
+	}
+
+	// Tags go into the title so submitForm parses them like typed ones
+	parts := []string{tmpl.Title}
+	for _, project := range tmpl.Projects {
+		parts = append(parts, "+"+project)
+	}
+	for _, context := range tmpl.Contexts {
+		parts = append(parts, "@"+context)
+	}
+
+	m.mode = AddMode
+	m.resetInputs()
+	m.titleInput.SetValue(strings.TrimSpace(strings.Join(parts, " ")))
+	m.descInput.SetValue(tmpl.Description)
+}
@@ -1,39 +1,110 @@
 package ui
 
 import (
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"awp/pkg/commands"
 	"awp/pkg/database"
 	"awp/pkg/utils"
 )
 
+// getSelectedItemIndex returns the index into m.items of the task under the cursor, or -1
 func (m *Model) getSelectedItemIndex() int {
 	cursor := m.table.Cursor()
-	rows := m.table.Rows()
-
-	if cursor >= len(rows) {
+	if cursor < 0 || cursor >= len(m.rowItems) {
 		return -1
 	}
+	return m.rowItems[cursor]
+}
 
-	selectedRow := rows[cursor]
+// isWriteKey reports whether msg triggers an action that changes the database
+func (m *Model) isWriteKey(msg tea.KeyMsg) bool {
+	return key.Matches(msg,
+		m.keyMap.ToggleStatus,
+		m.keyMap.AddTask,
+		m.keyMap.AddFromTemplate,
+		m.keyMap.EditTask,
+		m.keyMap.DeleteTask,
+		m.keyMap.RaisePriority,
+		m.keyMap.LowerPriority,
+		m.keyMap.ReopenToToday,
+		m.keyMap.PinTask,
+		m.keyMap.MarkWaiting,
+		m.keyMap.DeferOverdue,
+		m.keyMap.CopyToWeekday,
+		m.keyMap.ToggleReviewed,
+	)
+}
 
-	// Items that start with "[" are likely tasks (e.g., "[ ]" or "[x]")
-	if len(selectedRow) > 0 && strings.HasPrefix(selectedRow[0], "[") {
-		// Calculate task index by counting how many task rows are before the cursor
-		taskIdx := 0
-		for i := 0; i < cursor; i++ {
-			if len(rows[i]) > 0 && strings.HasPrefix(rows[i][0], "[") {
-				taskIdx++
-			}
-		}
-		return taskIdx
+// isSelectionKey reports whether msg triggers an action on the selected task
+func (m *Model) isSelectionKey(msg tea.KeyMsg) bool {
+	return key.Matches(msg,
+		m.keyMap.ToggleStatus,
+		m.keyMap.EditTask,
+		m.keyMap.DeleteTask,
+		m.keyMap.RaisePriority,
+		m.keyMap.LowerPriority,
+		m.keyMap.ReopenToToday,
+		m.keyMap.PinTask,
+		m.keyMap.MarkWaiting,
+		m.keyMap.CopyToWeekday,
+		m.keyMap.ToggleReviewed,
+	)
+}
+
+// hasSelection reports whether the cursor is on a task rather than on a group header or nothing
+func (m *Model) hasSelection() bool {
+	idx := m.getSelectedItemIndex()
+	return idx >= 0 && idx < len(m.items)
+}
+
+// noSelection handles a key that acts on the selected task while none is selected. On an empty list
+// the edit and delete keys open the add form if empty_list_opens_add is set.
+func (m *Model) noSelection(msg tea.KeyMsg) {
+	if len(m.items) == 0 && m.config.EmptyListOpensAdd && key.Matches(msg, m.keyMap.EditTask, m.keyMap.DeleteTask) {
+		m.mode = AddMode
+		m.resetInputs()
+		return
+	}
+	m.statusMsg = "No task selected"
+}
+
+// handleCountPrefix accumulates digits typed in normal mode and applies them to the following
+// motion: Nj/Nk move N rows, NG jumps to the Nth task. Any other key drops the prefix.
+// It reports whether the key was consumed.
+func (m *Model) handleCountPrefix(keyStr string) bool {
+	if len(keyStr) == 1 && keyStr[0] >= '0' && keyStr[0] <= '9' && (keyStr != "0" || m.countPrefix != "") {
+		m.countPrefix += keyStr
+		m.statusMsg = m.countPrefix
+		return true
+	}
+
+	if m.countPrefix == "" {
+		return false
 	}
 
-	return -1
+	count, _ := strconv.Atoi(m.countPrefix)
+	m.countPrefix = ""
+
+	switch keyStr {
+	case "j", "down":
+		m.table.MoveDown(count)
+	case "k", "up":
+		m.table.MoveUp(count)
+	case "G":
+		if count > len(m.items) {
+			count = len(m.items)
+		}
+		m.selectItem(count - 1)
+	default:
+		return false
+	}
+	return true
 }
 
 // Update handles messages and updates the model
@@ -43,8 +114,71 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Status notes and the startup banner only live until the next key press
+		m.statusMsg = ""
+		m.banner = ""
+
 		switch m.mode {
 		case NormalMode:
+			// The key after the copy-view key picks the format
+			if m.pendingCopy {
+				m.pendingCopy = false
+				switch msg.String() {
+				case "t":
+					m.copyView("todotxt")
+				case "m":
+					m.copyView("md")
+				default:
+					m.statusMsg = "Copy cancelled"
+				}
+				return m, nil
+			}
+
+			// The key after the copy-to-weekday key picks the weekday
+			if m.pendingWeekday {
+				m.pendingWeekday = false
+				if weekday, ok := weekdayLetters[msg.String()]; ok {
+					m.copyToWeekday(weekday)
+				} else {
+					m.statusMsg = "Copy cancelled"
+				}
+				return m, nil
+			}
+
+			// Number keys toggle chips while the chip bar is open; any other key closes it
+			if m.chipBarOpen {
+				if keyStr := msg.String(); len(keyStr) == 1 && keyStr[0] >= '0' && keyStr[0] <= '9' {
+					m.toggleChip(keyStr)
+				} else {
+					m.chipBarOpen = false
+				}
+				return m, nil
+			}
+
+			// Answer to the large all-tasks view prompt
+			if m.pendingAllView {
+				m.pendingAllView = false
+				if msg.String() == "y" || msg.String() == "Y" {
+					m.viewMode = database.AllViewMode
+					m.loadTasks()
+				}
+				return m, nil
+			}
+
+			// Answer to the defer-overdue prompt
+			if m.pendingDefer > 0 {
+				m.pendingDefer = 0
+				if msg.String() == "y" || msg.String() == "Y" {
+					m.deferOverdue()
+				}
+				return m, nil
+			}
+
+			// Collect a numeric prefix and apply it to the next motion
+			if handled := m.handleCountPrefix(msg.String()); handled {
+				return m, nil
+			}
+
 			switch {
 			case key.Matches(msg, m.keyMap.ShowHelp):
 				m.mode = HelpViewMode
@@ -52,75 +186,154 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case key.Matches(msg, m.keyMap.QuitApp):
 				return m, tea.Quit
 
+			case m.config.ReadOnly && m.isWriteKey(msg):
+				m.statusMsg = "Read-only mode: changes are disabled"
+
+			case m.isSelectionKey(msg) && !m.hasSelection():
+				m.noSelection(msg)
+
 			case key.Matches(msg, m.keyMap.JumpToToday):
+				if !m.config.JumpToTodayPreservesFilter {
+					m.taskFilter = database.AllTasksFilter
+					m.searchTerm = ""
+					m.searchInput.SetValue("")
+				}
 				m.loadTodaysTasks()
 
 			case key.Matches(msg, m.keyMap.ToggleStatus):
-				if len(m.items) > 0 {
-					idx := m.getSelectedItemIndex()
-					if idx != -1 && idx < len(m.items) {
-						m.items[idx].Status = !m.items[idx].Status
-						err := database.UpdateTaskStatus(m.db, m.items[idx].ID, m.items[idx].Status)
-						if err != nil {
-							m.err = err
-						} else {
-							m.loadTasks()
-						}
+				// Only the database is changed; the reload shows the new state once it is saved
+				toggled := m.items[m.getSelectedItemIndex()]
+				err := database.UpdateTaskState(m.db, toggled.ID, toggled.State.Next())
+				if err != nil {
+					m.writeFailed(err)
+				} else {
+					m.loadTasks()
+					if m.config.AdvanceAfterToggle {
+						m.advancePast(toggled.ID)
+					}
+					if toggled.State.Next() == database.StateDone {
+						m.noteCleared(toggled, false)
 					}
 				}
 				return m, nil
 
+			case key.Matches(msg, m.keyMap.DeferOverdue):
+				m.askDeferOverdue()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.PinTask):
+				m.togglePin()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.MarkWaiting):
+				m.askWaiting()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.ReopenToToday):
+				m.reopenToToday()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.RaisePriority):
+				m.changePriority(1)
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.LowerPriority):
+				m.changePriority(-1)
+				return m, nil
+
 			case key.Matches(msg, m.keyMap.AddTask):
 				m.mode = AddMode
 				m.resetInputs()
 
+			case key.Matches(msg, m.keyMap.AddFromTemplate):
+				if len(m.config.Templates) == 0 {
+					m.statusMsg = "No templates configured"
+				} else {
+					m.mode = TemplateMode
+					m.templateCursor = 0
+				}
+
 			case key.Matches(msg, m.keyMap.EditTask):
-				if len(m.items) > 0 {
-					idx := m.getSelectedItemIndex()
-					if idx != -1 && idx < len(m.items) {
-						m.mode = EditMode
-						m.editingItem = &m.items[idx]
-						m.resetInputs()
-
-						// Populate form with existing values
-						m.titleInput.SetValue(m.editingItem.Title)
-						m.descInput.SetValue(m.editingItem.Description)
-
-						// Format and set due date
-						if !m.editingItem.DueDate.IsZero() {
-							m.dueDateInput.SetValue(m.editingItem.DueDate.Format("2006-01-02"))
-						}
-					}
+				m.mode = EditMode
+				m.editingItem = &m.items[m.getSelectedItemIndex()]
+				m.resetInputs()
+				m.formState = m.editingItem.State
+
+				// Populate form with existing values
+				m.titleInput.SetValue(m.editingItem.Title)
+				m.descInput.SetValue(m.editingItem.Description)
+
+				// Format and set due date
+				if !m.editingItem.DueDate.IsZero() {
+					m.dueDateInput.SetValue(m.editingItem.DueDate.Format("2006-01-02"))
 				}
 
 			case key.Matches(msg, m.keyMap.DeleteTask):
-				if len(m.items) > 0 {
-					idx := m.getSelectedItemIndex()
-					if idx != -1 && idx < len(m.items) {
-						m.mode = DeleteConfirmMode
-						m.editingItem = &m.items[idx]
-					}
-				}
+				m.mode = DeleteConfirmMode
+				m.editingItem = &m.items[m.getSelectedItemIndex()]
 
 			case key.Matches(msg, m.keyMap.ToggleViewMode):
 				// Toggle between today's tasks and all tasks
 				if m.viewMode == database.TodayViewMode {
+					if m.confirmLargeAllView() {
+						return m, nil
+					}
 					m.viewMode = database.AllViewMode
 				} else {
 					m.viewMode = database.TodayViewMode
 				}
 				m.loadTasks()
 
+			case key.Matches(msg, m.keyMap.ToggleWeekView):
+				if m.viewMode == database.WeekViewMode {
+					m.viewMode = database.TodayViewMode
+				} else {
+					m.viewMode = database.WeekViewMode
+				}
+				m.loadTasks()
+
 			case key.Matches(msg, m.keyMap.PrevDay):
 				if m.viewMode == database.TodayViewMode {
-					m.viewDate = m.viewDate.AddDate(0, 0, -1)
+					m.setViewDate(m.stepDay(m.viewDate, -1))
+					m.loadTasks()
+				} else if m.viewMode == database.WeekViewMode {
+					m.setViewDate(m.viewDate.AddDate(0, 0, -7))
 					m.loadTasks()
 				}
 
 			case key.Matches(msg, m.keyMap.NextDay):
 				if m.viewMode == database.TodayViewMode {
-					m.viewDate = m.viewDate.AddDate(0, 0, 1)
+					m.setViewDate(m.stepDay(m.viewDate, 1))
 					m.loadTasks()
+				} else if m.viewMode == database.WeekViewMode {
+					m.setViewDate(m.viewDate.AddDate(0, 0, 7))
+					m.loadTasks()
+				}
+
+			case key.Matches(msg, m.keyMap.HistoryBack):
+				m.historyBack()
+
+			case key.Matches(msg, m.keyMap.HistoryForward):
+				m.historyForward()
+
+			case key.Matches(msg, m.keyMap.PrevWeekWithTasks):
+				if m.viewMode == database.TodayViewMode {
+					m.jumpToTasksBeyond(0, -7)
+				}
+
+			case key.Matches(msg, m.keyMap.NextWeekWithTasks):
+				if m.viewMode == database.TodayViewMode {
+					m.jumpToTasksBeyond(0, 7)
+				}
+
+			case key.Matches(msg, m.keyMap.PrevMonthWithTasks):
+				if m.viewMode == database.TodayViewMode {
+					m.jumpToTasksBeyond(-1, 0)
+				}
+
+			case key.Matches(msg, m.keyMap.NextMonthWithTasks):
+				if m.viewMode == database.TodayViewMode {
+					m.jumpToTasksBeyond(1, 0)
 				}
 
 			case key.Matches(msg, m.keyMap.PrevDayWithTasks):
@@ -151,6 +364,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				m.loadTasks()
 
+			case key.Matches(msg, m.keyMap.ShowUntaggedTasks):
+				// Toggle between untagged tasks and all tasks
+				if m.taskFilter == database.UntaggedTasksFilter {
+					m.taskFilter = database.AllTasksFilter
+				} else {
+					m.taskFilter = database.UntaggedTasksFilter
+				}
+				m.loadTasks()
+
+			case key.Matches(msg, m.keyMap.ShowUnreviewed):
+				// Toggle between unreviewed tasks and all tasks
+				if m.taskFilter == database.UnreviewedTasksFilter {
+					m.taskFilter = database.AllTasksFilter
+				} else {
+					m.taskFilter = database.UnreviewedTasksFilter
+				}
+				m.loadTasks()
+
+			case key.Matches(msg, m.keyMap.ToggleReviewed):
+				m.toggleReviewed()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.ShowWaitingTasks):
+				m.toggleWaitingSearch()
+
+			case key.Matches(msg, m.keyMap.ResetView):
+				m.resetView()
+				return m, nil
+
 			case key.Matches(msg, m.keyMap.SearchTasks):
 				// Enter search mode
 				m.mode = SearchMode
@@ -159,11 +401,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 
 			case key.Matches(msg, m.keyMap.ToggleSortBy):
-				m.sortBy = (m.sortBy + 1) % 7 // Cycle through all sort options
+				m.sortBy = (m.sortBy + 1) % database.SortBy(len(sortByNames)) // Cycle through all sort options
 				m.loadTasks()
 
+			case key.Matches(msg, m.keyMap.SortMenu):
+				m.mode = SortMenuMode
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.ExportTasks):
+				m.mode = ExportMenuMode
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.SwitchDatabase):
+				if len(m.config.Databases) == 0 {
+					m.statusMsg = "No databases configured"
+				} else {
+					m.mode = DatabaseMenuMode
+				}
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.TrashView):
+				m.openTrash()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.FilterGroups):
+				if m.groupBy == database.GroupByNone {
+					m.statusMsg = "Group the view first to filter groups"
+					return m, nil
+				}
+				m.mode = GroupFilterMode
+				m.groupFilterInput.SetValue(m.groupFilter)
+				m.groupFilterInput.Focus()
+				return m, nil
+
 			case key.Matches(msg, m.keyMap.ToggleGroupBy):
-				m.groupBy = (m.groupBy + 1) % 7 // Cycle through all group options
+				m.groupBy = (m.groupBy + 1) % database.GroupBy(len(groupByNames)) // Cycle through all group options
 				m.loadTasks()
 
 			case key.Matches(msg, m.keyMap.ToggleSortOrder):
@@ -174,6 +446,86 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				m.loadTasks()
 
+			case key.Matches(msg, m.keyMap.ToggleDueOrder):
+				// Switching to due date starts newest first; after that the key flips the order
+				if m.sortBy == database.SortByDueDate && m.sortOrder == database.SortDesc {
+					m.sortOrder = database.SortAsc
+				} else {
+					m.sortOrder = database.SortDesc
+				}
+				m.sortBy = database.SortByDueDate
+				m.loadTasks()
+
+			case key.Matches(msg, m.keyMap.ShareTasks):
+				m.shareTasks()
+
+			case key.Matches(msg, m.keyMap.PickRandomTask):
+				m.pickRandomTask()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.CommandPalette):
+				m.paletteInput.SetValue("")
+				m.paletteInput.Focus()
+				m.paletteCursor = 0
+				m.mode = PaletteMode
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.GotoTask):
+				m.gotoInput.SetValue("")
+				m.gotoInput.Focus()
+				m.mode = GotoIDMode
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.ToggleUTC):
+				m.toggleUTC()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.ProjectChips):
+				m.openChipBar()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.FocusProject):
+				m.toggleProjectFocus()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.OpenView):
+				openCmd := m.openView()
+				return m, openCmd
+
+			case key.Matches(msg, m.keyMap.ToggleWeekSidebar):
+				m.showWeekSidebar = !m.showWeekSidebar
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.GrowTable):
+				m.resizeTable(1)
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.ShrinkTable):
+				m.resizeTable(-1)
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.ToggleRowText):
+				m.showDescriptions = !m.showDescriptions
+				m.loadTasks()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.ToggleIDs):
+				m.showIDs = !m.showIDs
+				m.loadTasks()
+				return m, nil
+
+			case key.Matches(msg, m.keyMap.CopyView):
+				if len(m.items) == 0 {
+					m.statusMsg = "Nothing to copy"
+				} else {
+					m.pendingCopy = true
+					m.statusMsg = "Copy as: (t)odo.txt or (m)arkdown checklist"
+				}
+
+			case key.Matches(msg, m.keyMap.CopyToWeekday):
+				m.pendingWeekday = true
+				m.statusMsg = "Copy to next: (m)on (t)ue (w)ed thu(r) (f)ri (s)at s(u)n"
+
 			case key.Matches(msg, m.keyMap.ToggleCalendarView):
 				// Toggle calendar view mode
 				if m.viewMode == database.CalendarViewMode {
@@ -190,65 +542,46 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Calendar navigation (only when in calendar view)
 			case key.Matches(msg, m.keyMap.CalendarLeft) && m.viewMode == database.CalendarViewMode:
-				if m.calendarSelectedDay > 1 {
-					m.calendarSelectedDay--
-				} else {
-					// Move to previous month and set to last day
-					m.calendarMonth = m.calendarMonth.AddDate(0, -1, 0)
-					lastDay := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month()+1, 0, 0, 0, 0, 0, m.calendarMonth.Location())
-					m.calendarSelectedDay = lastDay.Day()
-				}
+				m.moveCalendarSelection(-1)
 
 			case key.Matches(msg, m.keyMap.CalendarRight) && m.viewMode == database.CalendarViewMode:
-				lastDay := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month()+1, 0, 0, 0, 0, 0, m.calendarMonth.Location())
-				if m.calendarSelectedDay < lastDay.Day() {
-					m.calendarSelectedDay++
-				} else {
-					// Move to next month and set to first day
-					m.calendarMonth = m.calendarMonth.AddDate(0, 1, 0)
-					m.calendarSelectedDay = 1
-				}
+				m.moveCalendarSelection(1)
 
 			case key.Matches(msg, m.keyMap.CalendarUp) && m.viewMode == database.CalendarViewMode:
-				newDay := m.calendarSelectedDay - 7
-				if newDay < 1 {
-					// Move to previous month
-					m.calendarMonth = m.calendarMonth.AddDate(0, -1, 0)
-					lastDay := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month()+1, 0, 0, 0, 0, 0, m.calendarMonth.Location())
-					m.calendarSelectedDay = lastDay.Day() + newDay
-					if m.calendarSelectedDay < 1 {
-						m.calendarSelectedDay = 1
-					}
-				} else {
-					m.calendarSelectedDay = newDay
-				}
+				m.moveCalendarSelection(-7)
 
 			case key.Matches(msg, m.keyMap.CalendarDown) && m.viewMode == database.CalendarViewMode:
-				lastDay := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month()+1, 0, 0, 0, 0, 0, m.calendarMonth.Location())
-				newDay := m.calendarSelectedDay + 7
-				if newDay > lastDay.Day() {
-					// Move to next month
-					m.calendarMonth = m.calendarMonth.AddDate(0, 1, 0)
-					m.calendarSelectedDay = newDay - lastDay.Day()
-					// Cap to last day of the next month
-					nextMonthLastDay := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month()+1, 0, 0, 0, 0, 0, m.calendarMonth.Location())
-					if m.calendarSelectedDay > nextMonthLastDay.Day() {
-						m.calendarSelectedDay = nextMonthLastDay.Day()
-					}
+				m.moveCalendarSelection(7)
+
+			case key.Matches(msg, m.keyMap.CalendarLayout) && m.viewMode == database.CalendarViewMode:
+				if m.calendarLayout == MonthLayout {
+					m.calendarLayout = WeekLayout
 				} else {
-					m.calendarSelectedDay = newDay
+					m.calendarLayout = MonthLayout
 				}
 
 			case key.Matches(msg, m.keyMap.CalendarSelect) && m.viewMode == database.CalendarViewMode:
 				// Jump to selected day in today view
 				selectedDate := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month(), m.calendarSelectedDay, 0, 0, 0, 0, m.calendarMonth.Location())
-				m.viewDate = selectedDate
+				m.setViewDate(selectedDate)
 				m.viewMode = database.TodayViewMode
 				m.loadTasks()
 
+			case key.Matches(msg, m.keyMap.ZoomGroup) && m.viewMode != database.CalendarViewMode:
+				m.zoomIntoGroup()
+
+			case msg.String() == "esc" && m.groupFilter != "":
+				// Clear the group filter
+				m.groupFilter = ""
+				m.loadTasks()
+
+			case msg.String() == "esc" && m.zoomPrev != nil:
+				// Return from a zoomed project group
+				m.zoomOut()
+
 			case msg.String() == "esc" && m.viewMode == database.CalendarViewMode:
 				// Return to today view from calendar
-				m.viewDate = time.Now()
+				m.setViewDate(m.today())
 				m.viewMode = database.TodayViewMode
 				m.loadTasks()
 
@@ -261,6 +594,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case AddMode, EditMode:
+			// The date picker takes all keys while it is open
+			if m.pickingDate {
+				m.updateDatePicker(msg)
+				return m, nil
+			}
+
+			if m.activeInput == 2 && key.Matches(msg, m.keyMap.PickDate) {
+				m.openDatePicker()
+				return m, nil
+			}
+
+			if key.Matches(msg, m.keyMap.SaveForm) {
+				m.submitForm()
+				return m, nil
+			}
+
 			switch msg.String() {
 			case "esc":
 				m.mode = NormalMode
@@ -274,11 +623,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.focusPreviousInput()
 
 			case "enter":
-				if m.activeInput == 2 { // Submit on enter from the last field (due date)
+				// Submit on enter from the due date or status field, or from any field if configured
+				if m.activeInput >= 2 || m.config.SubmitOnEnter {
 					m.submitForm()
 				} else {
 					m.focusNextInput()
 				}
+
+			case " ", "x":
+				// Space or x cycles the status while the status field has the focus
+				if m.activeInput == statusField {
+					m.formState = m.formState.Next()
+				}
 			}
 
 			// Handle input updates
@@ -315,6 +671,193 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.searchInput, cmd = m.searchInput.Update(msg)
 			cmds = append(cmds, cmd)
 
+		case GroupFilterMode:
+			switch msg.String() {
+			case "esc":
+				m.mode = NormalMode
+				m.groupFilter = ""
+				m.groupFilterInput.Blur()
+				m.loadTasks()
+				return m, nil
+
+			case "enter":
+				m.mode = NormalMode
+				m.groupFilterInput.Blur()
+				return m, nil
+			}
+
+			// Filter the groups as the user types
+			m.groupFilterInput, cmd = m.groupFilterInput.Update(msg)
+			cmds = append(cmds, cmd)
+			m.groupFilter = strings.TrimSpace(m.groupFilterInput.Value())
+			m.loadTasks()
+
+		case WaitingMode:
+			switch msg.String() {
+			case "esc":
+				m.mode = NormalMode
+				m.waitingInput.Blur()
+				return m, nil
+
+			case "enter":
+				m.setWaiting()
+				return m, nil
+			}
+
+			m.waitingInput, cmd = m.waitingInput.Update(msg)
+			cmds = append(cmds, cmd)
+
+		case PaletteMode:
+			actions := m.paletteActions()
+			switch msg.String() {
+			case "esc":
+				m.mode = NormalMode
+				m.paletteInput.Blur()
+				return m, nil
+
+			case "up", "ctrl+k":
+				if m.paletteCursor > 0 {
+					m.paletteCursor--
+				}
+				return m, nil
+
+			case "down", "ctrl+j":
+				if m.paletteCursor < len(actions)-1 {
+					m.paletteCursor++
+				}
+				return m, nil
+
+			case "enter":
+				m.mode = NormalMode
+				m.paletteInput.Blur()
+				if m.paletteCursor < len(actions) {
+					// Run the action as if its key had been pressed
+					return m.Update(keyMsgFor(m.keyMap.Actions[actions[m.paletteCursor]].Keys()[0]))
+				}
+				return m, nil
+			}
+
+			m.paletteInput, cmd = m.paletteInput.Update(msg)
+			cmds = append(cmds, cmd)
+			m.paletteCursor = 0
+
+		case GotoIDMode:
+			switch msg.String() {
+			case "esc":
+				m.mode = NormalMode
+				m.gotoInput.Blur()
+				return m, nil
+
+			case "enter":
+				m.gotoTask()
+				return m, nil
+			}
+
+			m.gotoInput, cmd = m.gotoInput.Update(msg)
+			cmds = append(cmds, cmd)
+
+		case ExportMenuMode:
+			switch keyStr := msg.String(); keyStr {
+			case "esc":
+				m.mode = NormalMode
+
+			case "a":
+				m.exportAllTasks = !m.exportAllTasks
+
+			default:
+				// Number keys pick the format and export right away
+				if len(keyStr) == 1 && keyStr[0] >= '1' && int(keyStr[0]-'1') < len(commands.ExportTypes) {
+					m.mode = NormalMode
+					m.exportTasks(commands.ExportTypes[keyStr[0]-'1'])
+				}
+			}
+			return m, nil
+
+		case DatabaseMenuMode:
+			keyStr := msg.String()
+			names := m.databaseNames()
+			if keyStr == "esc" {
+				m.mode = NormalMode
+			} else if len(keyStr) == 1 && keyStr[0] >= '1' && int(keyStr[0]-'1') < len(names) {
+				// Number keys pick the list and switch right away
+				m.mode = NormalMode
+				m.switchDatabase(names[keyStr[0]-'1'])
+			}
+			return m, nil
+
+		case SortMenuMode:
+			switch keyStr := msg.String(); keyStr {
+			case "esc", "enter":
+				m.mode = NormalMode
+
+			case "o":
+				if m.sortOrder == database.SortAsc {
+					m.sortOrder = database.SortDesc
+				} else {
+					m.sortOrder = database.SortAsc
+				}
+				m.loadTasks()
+
+			default:
+				// Number keys pick the sort field and close the menu
+				if len(keyStr) == 1 && keyStr[0] >= '1' && int(keyStr[0]-'1') < len(sortByNames) {
+					m.sortBy = database.SortBy(keyStr[0] - '1')
+					m.mode = NormalMode
+					m.loadTasks()
+				}
+			}
+			return m, nil
+
+		case TemplateMode:
+			names := m.templateNames()
+			switch keyStr := msg.String(); keyStr {
+			case "esc":
+				m.mode = NormalMode
+
+			case "up", "k":
+				if m.templateCursor > 0 {
+					m.templateCursor--
+				}
+
+			case "down", "j":
+				if m.templateCursor < len(names)-1 {
+					m.templateCursor++
+				}
+
+			case "enter":
+				m.applyTemplate(names[m.templateCursor])
+
+			default:
+				// Number keys pick a template directly
+				if len(keyStr) == 1 && keyStr[0] >= '1' && keyStr[0] <= '9' {
+					if n := int(keyStr[0] - '1'); n < len(names) {
+						m.applyTemplate(names[n])
+					}
+				}
+			}
+			return m, nil
+
+		case TrashMode:
+			switch msg.String() {
+			case "esc":
+				m.mode = NormalMode
+				m.trashItems = nil
+
+			case "up", "k":
+				if m.trashCursor > 0 {
+					m.trashCursor--
+				}
+
+			case "down", "j":
+				if m.trashCursor < len(m.trashItems)-1 {
+					m.trashCursor++
+				}
+
+			case "enter":
+				m.restoreFromTrash()
+			}
+			return m, nil
+
 		case DeleteConfirmMode:
 			// Handle delete confirmation
 			switch msg.String() {
@@ -324,11 +867,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					// Delete from database using the database function
 					err := database.DeleteTask(m.db, m.editingItem.ID)
 					if err != nil {
-						utils.Log("Error deleting task: %v", err)
-						m.err = err
+						m.writeFailed(err)
 					} else {
-						utils.Log("Task deleted successfully")
+						utils.Log("Task moved to trash")
+						deleted := *m.editingItem
 						m.loadTasks()
+						m.statusMsg = "Moved to trash (T to restore)"
+						m.noteCleared(deleted, true)
 					}
 				}
 				m.mode = NormalMode
@@ -351,10 +896,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case editorFinishedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+		}
+
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
 		m.table.SetWidth(msg.Width - 4)
-		m.table.SetHeight(msg.Height - 4)
+		m.applyTableHeight()
 	}
 
 	// Only update table in normal mode
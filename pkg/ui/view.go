@@ -2,19 +2,33 @@ package ui
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
 
+	"awp/pkg/commands"
 	"awp/pkg/database"
+	"awp/pkg/utils"
 )
 
 // View renders the UI based on the current mode
 func (m Model) View() string {
 	var sb strings.Builder
 
+	// Startup alert about overdue tasks
+	if m.banner != "" {
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.ErrorColor)).
+			Render(m.banner))
+		sb.WriteString("\n\n")
+	}
+
 	switch m.mode {
 	case NormalMode:
 		switch m.viewMode {
@@ -37,8 +51,27 @@ func (m Model) View() string {
 			// Table view code - no outer border
 			tableStyle := lipgloss.NewStyle()
 
-			// Table with tasks
-			sb.WriteString(tableStyle.Render(m.table.View()))
+			// Table with tasks, with the week sidebar beside it if there is room
+			tableView := m.table.View()
+			if sidebar := m.renderWeekSidebar(); sidebar != "" {
+				narrowed := m.table
+				narrowed.SetWidth(m.table.Width() - lipgloss.Width(sidebar) - 2)
+				tableView = lipgloss.JoinHorizontal(lipgloss.Top, narrowed.View(), "  ", sidebar)
+			}
+			sb.WriteString(tableStyle.Render(tableView))
+			sb.WriteString("\n")
+
+			if m.chipBarOpen || len(m.chipProjects) > 0 {
+				sb.WriteString(m.renderChipBar())
+				sb.WriteString("\n")
+			}
+
+			if m.config.ShowProgressBar {
+				sb.WriteString(m.renderProgressBar())
+				sb.WriteString("\n")
+			}
+
+			sb.WriteString(m.renderTotals())
 			sb.WriteString("\n")
 
 			// Display view mode and date
@@ -51,6 +84,13 @@ func (m Model) View() string {
 				viewModePart = "all tasks"
 			case database.TodayViewMode:
 				viewModePart = fmt.Sprintf("tasks due on %s", m.viewDate.Format("2006-01-02"))
+				if m.config.ShowWeekNumber {
+					viewModePart += fmt.Sprintf(" (week %d)", m.weekNumber(m.viewDate))
+				}
+			case database.WeekViewMode:
+				start := m.startOfWeek(m.viewDate)
+				viewModePart = fmt.Sprintf("tasks due in week %d (%s to %s)", m.weekNumber(start),
+					start.Format("2006-01-02"), start.AddDate(0, 0, 6).Format("2006-01-02"))
 			}
 
 			// Build the filter part
@@ -62,6 +102,10 @@ func (m Model) View() string {
 				filterPart = " (completed only)"
 			case database.UndoneTasksFilter:
 				filterPart = " (pending only)"
+			case database.UntaggedTasksFilter:
+				filterPart = " (untagged only)"
+			case database.UnreviewedTasksFilter:
+				filterPart = " (unreviewed only)"
 			}
 
 			// show search filter
@@ -71,8 +115,8 @@ func (m Model) View() string {
 
 			// Add sorting/grouping info to view status
 			sortInfo := ""
-			if m.sortBy != database.SortByDueDate || m.groupBy != database.GroupByNone {
-				sortByStr := []string{"title", "description", "due date", "project", "context", "created", "status"}[m.sortBy]
+			if m.sortBy != database.SortByDueDate || m.sortOrder != database.SortAsc || m.groupBy != database.GroupByNone {
+				sortByStr := sortByNames[m.sortBy]
 				orderStr := "asc"
 				if m.sortOrder == database.SortDesc {
 					orderStr = "desc"
@@ -80,8 +124,10 @@ func (m Model) View() string {
 
 				groupByStr := ""
 				if m.groupBy != database.GroupByNone {
-					groupOptions := []string{"", "project", "context", "daily", "weekly", "monthly", "yearly"}
-					groupByStr = fmt.Sprintf(", grouped by %s", groupOptions[m.groupBy])
+					groupByStr = fmt.Sprintf(", grouped by %s", groupByNames[m.groupBy])
+					if m.groupFilter != "" {
+						groupByStr += fmt.Sprintf(" matching %q", m.groupFilter)
+					}
 				}
 
 				sortInfo = fmt.Sprintf(" | sorted by %s (%s)%s", sortByStr, orderStr, groupByStr)
@@ -89,8 +135,33 @@ func (m Model) View() string {
 
 			// Combine the parts
 			viewInfo = fmt.Sprintf("Showing %s%s%s", viewModePart, filterPart, sortInfo)
+			if m.focusProject != "" {
+				viewInfo = fmt.Sprintf("[focus: +%s] %s", m.focusProject, viewInfo)
+			}
+			if m.useUTC {
+				viewInfo = "[UTC] " + viewInfo
+			}
+			if m.config.ReadOnly {
+				viewInfo = "[read-only] " + viewInfo
+			}
+			if name := m.activeDatabaseName(); name != "" {
+				viewInfo = fmt.Sprintf("[list: %s] %s", name, viewInfo)
+			}
 			sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.NormalTextColor)).Render(viewInfo))
 			sb.WriteString("\n")
+
+			// Tell when a broad search was cut short
+			if m.searchMatches > 0 {
+				sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.AccentColor)).Render(
+					fmt.Sprintf("Showing first %d of %d — refine your search", m.config.MaxSearchResults, m.searchMatches)))
+				sb.WriteString("\n")
+			}
+
+			// Show the latest status note, if any
+			if m.statusMsg != "" {
+				sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.AccentColor)).Render(m.statusMsg))
+				sb.WriteString("\n")
+			}
 		}
 
 	case AddMode:
@@ -123,7 +194,7 @@ func (m Model) View() string {
 		sb.WriteString("\n\n")
 
 		if m.editingItem != nil {
-			sb.WriteString(fmt.Sprintf("Are you sure you want to delete this task?\n\n"))
+			sb.WriteString("Move this task to the trash?\n\n")
 			sb.WriteString(fmt.Sprintf("Title: %s\n", m.editingItem.Title))
 			sb.WriteString(fmt.Sprintf("Description: %s\n", m.editingItem.Description))
 			sb.WriteString("\n")
@@ -142,6 +213,199 @@ func (m Model) View() string {
 		sb.WriteString("\n\n")
 		sb.WriteString(m.searchInput.View())
 
+	case GroupFilterMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Filter Groups "))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.table.View())
+		sb.WriteString("\n\n")
+		sb.WriteString(m.groupFilterInput.View())
+
+	case TemplateMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Add From Template "))
+		sb.WriteString("\n\n")
+
+		for i, name := range m.templateNames() {
+			line := fmt.Sprintf("%d. %s", i+1, name)
+			if tmpl := m.config.Templates[name]; tmpl.Title != "" {
+				line += " - " + tmpl.Title
+			}
+			if i == m.templateCursor {
+				line = lipgloss.NewStyle().
+					Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+					Background(lipgloss.Color(m.styles.SelectedBgColor)).
+					Render(line)
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+
+	case PaletteMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Command Palette "))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.paletteInput.View())
+		sb.WriteString("\n\n")
+
+		actions := m.paletteActions()
+		if len(actions) == 0 {
+			sb.WriteString("No matching action\n")
+		}
+
+		// Keep the selected action in a window of the list
+		first := max(0, m.paletteCursor-maxPaletteRows+1)
+		for i := first; i < len(actions) && i < first+maxPaletteRows; i++ {
+			binding := m.keyMap.Actions[actions[i]]
+			line := fmt.Sprintf("%s  %s", binding.Help().Desc, binding.Help().Key)
+			if i == m.paletteCursor {
+				line = lipgloss.NewStyle().
+					Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+					Background(lipgloss.Color(m.styles.SelectedBgColor)).
+					Render(line)
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+
+	case TrashMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Trash "))
+		sb.WriteString("\n\n")
+
+		if len(m.trashItems) == 0 {
+			sb.WriteString("The trash is empty.\n")
+		}
+		for i, item := range m.trashItems {
+			line := fmt.Sprintf("%s  %s", item.DeletedAt.In(utils.Location()).Format("2006-01-02 15:04"), item.Title)
+			if i == m.trashCursor {
+				line = lipgloss.NewStyle().
+					Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+					Background(lipgloss.Color(m.styles.SelectedBgColor)).
+					Render(line)
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+		if m.statusMsg != "" {
+			sb.WriteString("\n")
+			sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.ErrorColor)).Render(m.statusMsg))
+		}
+
+	case WaitingMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Waiting For "))
+		sb.WriteString("\n\n")
+		sb.WriteString("Hide the task until the follow-up date:")
+		sb.WriteString("\n\n")
+		sb.WriteString(m.waitingInput.View())
+		if m.statusMsg != "" {
+			sb.WriteString("\n\n")
+			sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.ErrorColor)).Render(m.statusMsg))
+		}
+
+	case GotoIDMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Go To Task "))
+		sb.WriteString("\n\n")
+		sb.WriteString(m.gotoInput.View())
+		if m.statusMsg != "" {
+			sb.WriteString("\n\n")
+			sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.ErrorColor)).Render(m.statusMsg))
+		}
+
+	case ExportMenuMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Export Tasks "))
+		sb.WriteString("\n\n")
+
+		for i, exportType := range commands.ExportTypes {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, exportType))
+		}
+
+		scope := fmt.Sprintf("tasks in view (%d)", len(m.items))
+		if m.exportAllTasks {
+			scope = "all tasks"
+		}
+		sb.WriteString(fmt.Sprintf("\na. scope: %s\n", scope))
+
+	case DatabaseMenuMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Switch Task List "))
+		sb.WriteString("\n\n")
+
+		active := m.activeDatabaseName()
+		for i, name := range m.databaseNames() {
+			line := fmt.Sprintf("%d. %s - %s", i+1, name, m.config.Databases[name])
+			if name == active {
+				line = lipgloss.NewStyle().
+					Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+					Background(lipgloss.Color(m.styles.SelectedBgColor)).
+					Render(line)
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+
+	case SortMenuMode:
+		sb.WriteString(lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+			Background(lipgloss.Color(m.styles.AccentColor)).
+			Padding(0, 1).
+			Render(" Sort By "))
+		sb.WriteString("\n\n")
+
+		for i, name := range sortByNames {
+			line := fmt.Sprintf("%d. %s", i+1, name)
+			if database.SortBy(i) == m.sortBy {
+				line = lipgloss.NewStyle().
+					Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+					Background(lipgloss.Color(m.styles.SelectedBgColor)).
+					Render(line)
+			}
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+
+		orderStr := "ascending"
+		if m.sortOrder == database.SortDesc {
+			orderStr = "descending"
+		}
+		sb.WriteString(fmt.Sprintf("\no. order: %s\n", orderStr))
+
 	case HelpViewMode:
 		// Fullscreen commands view
 		sb.WriteString(lipgloss.NewStyle().Bold(true).Render("Available Commands"))
@@ -171,20 +435,57 @@ func (m Model) View() string {
 		// Add all commands line by line
 		addCommand(m.keyMap.QuitApp)
 		addCommand(m.keyMap.ShowHelp)
+		addCommand(m.keyMap.CommandPalette)
 		addCommand(m.keyMap.ToggleStatus)
+		addCommand(m.keyMap.ReopenToToday)
+		addCommand(m.keyMap.PinTask)
+		addCommand(m.keyMap.MarkWaiting)
+		addCommand(m.keyMap.DeferOverdue)
+		addCommand(m.keyMap.RaisePriority)
+		addCommand(m.keyMap.LowerPriority)
 		addCommand(m.keyMap.AddTask)
+		addCommand(m.keyMap.AddFromTemplate)
 		addCommand(m.keyMap.EditTask)
+		addCommand(m.keyMap.PickDate)
+		addCommand(m.keyMap.SaveForm)
 		addCommand(m.keyMap.DeleteTask)
 		addCommand(m.keyMap.ToggleViewMode)
 		addCommand(m.keyMap.ShowDoneTasks)
 		addCommand(m.keyMap.ShowUndoneTasks)
+		addCommand(m.keyMap.ShowUntaggedTasks)
+		addCommand(m.keyMap.ShowUnreviewed)
+		addCommand(m.keyMap.ToggleReviewed)
+		addCommand(m.keyMap.ShowWaitingTasks)
 		addCommand(m.keyMap.SearchTasks)
+		addCommand(m.keyMap.ResetView)
 		addCommand(m.keyMap.ToggleCalendarView)
+		addCommand(m.keyMap.ShareTasks)
+		addCommand(m.keyMap.CopyView)
+		addCommand(m.keyMap.ExportTasks)
+		addCommand(m.keyMap.OpenView)
+		addCommand(m.keyMap.PickRandomTask)
+		addCommand(m.keyMap.GotoTask)
+		addCommand(m.keyMap.FocusProject)
+		addCommand(m.keyMap.ProjectChips)
+		addCommand(m.keyMap.ToggleUTC)
+		addCommand(m.keyMap.ToggleRowText)
+		addCommand(m.keyMap.ToggleIDs)
+		addCommand(m.keyMap.ToggleWeekSidebar)
+		addCommand(m.keyMap.SwitchDatabase)
+		addCommand(m.keyMap.GrowTable)
+		addCommand(m.keyMap.ShrinkTable)
+		addCommand(m.keyMap.TrashView)
+		addCommand(m.keyMap.CopyToWeekday)
+		addCommand(m.keyMap.ToggleWeekView)
 
 		// add command for toggling sort by
 		addCommand(m.keyMap.ToggleSortBy)
+		addCommand(m.keyMap.SortMenu)
 		addCommand(m.keyMap.ToggleGroupBy)
+		addCommand(m.keyMap.FilterGroups)
 		addCommand(m.keyMap.ToggleSortOrder)
+		addCommand(m.keyMap.ToggleDueOrder)
+		addCommand(m.keyMap.ZoomGroup)
 
 		// Navigation commands
 		sb.WriteString("\n")
@@ -195,6 +496,12 @@ func (m Model) View() string {
 		addCommand(m.keyMap.NextDay)
 		addCommand(m.keyMap.PrevDayWithTasks)
 		addCommand(m.keyMap.NextDayWithTasks)
+		addCommand(m.keyMap.PrevWeekWithTasks)
+		addCommand(m.keyMap.NextWeekWithTasks)
+		addCommand(m.keyMap.PrevMonthWithTasks)
+		addCommand(m.keyMap.NextMonthWithTasks)
+		addCommand(m.keyMap.HistoryBack)
+		addCommand(m.keyMap.HistoryForward)
 
 		// Calendar commands
 		sb.WriteString("\n")
@@ -204,6 +511,7 @@ func (m Model) View() string {
 		addCommand(m.keyMap.CalendarRight)
 		addCommand(m.keyMap.CalendarUp)
 		addCommand(m.keyMap.CalendarDown)
+		addCommand(m.keyMap.CalendarLayout)
 		addCommand(m.keyMap.JumpToToday)
 
 	}
@@ -245,6 +553,7 @@ func (m Model) helpBar() string {
 			addAction("←↑↓→", "nav")
 			addAction("enter", "select")
 			addAction("h", "today")
+			addAction(m.keyMap.CalendarLayout.Help().Key, "layout")
 			addAction("ctrl+c", "exit cal")
 		} else {
 			addAction("a", "add")
@@ -260,8 +569,25 @@ func (m Model) helpBar() string {
 		addAction("q", "quit")
 
 	case AddMode, EditMode:
+		if m.pickingDate {
+			addAction("←→↑↓", "move")
+			addAction("enter", "pick date")
+			addAction("esc", "keep typed date")
+			break
+		}
 		addAction("tab", "next field")
-		addAction("enter", "save")
+		if m.activeInput == statusField {
+			addAction("space/x", "change status")
+		}
+		if m.activeInput >= 2 || m.config.SubmitOnEnter {
+			addAction("enter", "save")
+		} else {
+			addAction("enter", "next field")
+		}
+		addAction(m.keyMap.SaveForm.Help().Key, "save")
+		if m.activeInput == 2 {
+			addAction(m.keyMap.PickDate.Help().Key, "pick date")
+		}
 		addAction("esc", "cancel")
 
 	case DeleteConfirmMode:
@@ -272,6 +598,45 @@ func (m Model) helpBar() string {
 		addAction("enter", "search")
 		addAction("esc", "cancel")
 
+	case GroupFilterMode:
+		addAction("enter", "keep filter")
+		addAction("esc", "clear filter")
+
+	case WaitingMode:
+		addAction("enter", "save")
+		addAction("esc", "cancel")
+
+	case GotoIDMode:
+		addAction("enter", "go")
+		addAction("esc", "cancel")
+
+	case ExportMenuMode:
+		addAction(fmt.Sprintf("1-%d", len(commands.ExportTypes)), "export")
+		addAction("a", "scope")
+		addAction("esc", "cancel")
+
+	case DatabaseMenuMode:
+		addAction(fmt.Sprintf("1-%d", min(len(m.config.Databases), 9)), "switch")
+		addAction("esc", "cancel")
+
+	case SortMenuMode:
+		addAction("1-8", "sort field")
+		addAction("o", "order")
+		addAction("enter/esc", "close")
+
+	case PaletteMode:
+		addAction("↑↓", "select")
+		addAction("enter", "run")
+		addAction("esc", "cancel")
+
+	case TrashMode:
+		addAction("enter", "restore")
+		addAction("esc", "back")
+
+	case TemplateMode:
+		addAction("1-9/enter", "use template")
+		addAction("esc", "cancel")
+
 	case HelpViewMode:
 		addAction("ctrl+b/esc", "back")
 		addAction("q", "quit")
@@ -280,6 +645,151 @@ func (m Model) helpBar() string {
 	return strings.Join(actions, separator)
 }
 
+// renderTotals renders a summary line of the tasks in the current view
+func (m Model) renderTotals() string {
+	done, overdue, waiting := 0, 0, 0
+	for _, item := range m.items {
+		switch {
+		case item.Status:
+			done++
+		case m.hasWaitingContext(item):
+			waiting++ // Delegated, so neither pending nor overdue
+		case m.isOverdue(item):
+			overdue++
+		}
+	}
+
+	numStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.AccentColor))
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.NormalTextColor))
+
+	parts := []string{
+		numStyle.Render(fmt.Sprintf("%d", len(m.items))) + textStyle.Render(" tasks"),
+		numStyle.Render(fmt.Sprintf("%d", done)) + textStyle.Render(" done"),
+		numStyle.Render(fmt.Sprintf("%d", len(m.items)-done-waiting)) + textStyle.Render(" pending"),
+		numStyle.Render(fmt.Sprintf("%d", overdue)) + textStyle.Render(" overdue"),
+	}
+	if waiting > 0 {
+		parts = append(parts, numStyle.Render(fmt.Sprintf("%d", waiting))+textStyle.Render(" waiting"))
+	}
+	if m.groupBy != database.GroupByNone {
+		parts = append(parts, numStyle.Render(fmt.Sprintf("%d", len(m.rowGroups)))+textStyle.Render(" groups"))
+	}
+
+	return strings.Join(parts, textStyle.Render(" · "))
+}
+
+// renderChipBar renders the project chips, numbered while the bar is open, with active ones highlighted
+func (m Model) renderChipBar() string {
+	activeStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+		Background(lipgloss.Color(m.styles.ProjectColor)).
+		Padding(0, 1)
+	inactiveStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(m.styles.ProjectColor)).
+		Padding(0, 1)
+
+	names := m.chipNames
+	if !m.chipBarOpen {
+		names = m.chipProjects // Only the active filter once the bar is closed
+	}
+
+	var chips []string
+	for i, name := range names {
+		label := "+" + name
+		if m.chipBarOpen {
+			label = fmt.Sprintf("%d +%s", i+1, name)
+		}
+		if slices.Contains(m.chipProjects, name) {
+			chips = append(chips, activeStyle.Render(label))
+		} else {
+			chips = append(chips, inactiveStyle.Render(label))
+		}
+	}
+
+	bar := strings.Join(chips, " ")
+	if m.chipBarOpen {
+		bar += lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.NormalTextColor)).Render("  (0 clears, any other key closes)")
+	}
+	return bar
+}
+
+// minSidebarTableWidth is the narrowest the table may get before the week sidebar is hidden
+const minSidebarTableWidth = 40
+
+// renderWeekSidebar renders the pending task count of each of the next 7 days, or "" when it is
+// turned off or the terminal is too narrow for it
+func (m Model) renderWeekSidebar() string {
+	if !m.showWeekSidebar {
+		return ""
+	}
+
+	today := m.today()
+	counts, err := database.CountTasksByDay(m.db, "status = 0", today, today.AddDate(0, 0, 6))
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("Next 7 days")}
+	for i := 0; i < 7; i++ {
+		day := today.AddDate(0, 0, i)
+		line := fmt.Sprintf("%s %3d", day.Format("Mon"), counts[day.Format("2006-01-02")])
+		if i == 0 {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.AccentColor)).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	sidebar := lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(lipgloss.Color(m.styles.BorderColor)).
+		PaddingLeft(1).
+		Render(strings.Join(lines, "\n"))
+
+	if m.width > 0 && m.width-4-lipgloss.Width(sidebar)-2 < minSidebarTableWidth {
+		return ""
+	}
+	return sidebar
+}
+
+// renderProgressBar renders a bar showing the ratio of done tasks in the current view
+func (m Model) renderProgressBar() string {
+	total := len(m.items)
+	done := 0
+	for _, item := range m.items {
+		if item.Status {
+			done++
+		}
+	}
+
+	// Round down so the bar is only full when every task is done
+	percent := 0
+	if total > 0 {
+		percent = done * 100 / total
+	}
+	label := fmt.Sprintf(" %d/%d (%d%%)", done, total, percent)
+
+	barWidth := m.width - 4 - len(label)
+	if m.width == 0 {
+		barWidth = 40
+	}
+	if barWidth < 10 {
+		barWidth = 10
+	}
+
+	filled := 0
+	if total > 0 {
+		filled = done * barWidth / total
+	}
+
+	filledStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.AccentColor))
+	emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.BorderColor))
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.NormalTextColor))
+
+	return filledStyle.Render(strings.Repeat("█", filled)) +
+		emptyStyle.Render(strings.Repeat("░", barWidth-filled)) +
+		labelStyle.Render(label)
+}
+
 // renderForm renders the input form for adding/editing tasks
 func (m Model) renderForm() string {
 	var sb strings.Builder
@@ -300,11 +810,67 @@ func (m Model) renderForm() string {
 	sb.WriteString("Due Date (YYYY-MM-DD):\n")
 	sb.WriteString(m.dueDateInput.View())
 
+	// Date picker opened from the due date field
+	if m.pickingDate {
+		sb.WriteString("\n\n")
+		sb.WriteString(m.renderCalendar())
+	}
+
+	// Status, edited like a checkbox
+	if m.mode == EditMode {
+		status := fmt.Sprintf("%s %s", stateMarker(m.formState), stateName(m.formState))
+		if m.activeInput == statusField {
+			status = lipgloss.NewStyle().
+				Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+				Background(lipgloss.Color(m.styles.SelectedBgColor)).
+				Render(status)
+		}
+		sb.WriteString("\n\nStatus:\n")
+		sb.WriteString(status)
+	}
+
+	// Note the tag inherited from the current filter
+	if tag := m.inheritedTag(); m.mode == AddMode && tag != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.NormalTextColor)).
+			Render(fmt.Sprintf("New task will be tagged %s", tag)))
+	}
+
+	// Show warnings such as a duplicate task
+	if m.statusMsg != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.AccentColor)).Render(m.statusMsg))
+	}
+
 	return formStyle.Render(sb.String())
 }
 
+// calendarCellWidth returns the width of a calendar cell: the widest label plus a one-column gap
+func calendarCellWidth(weekdays []string, dayLabels []string) int {
+	width := 0
+	for _, label := range append(append([]string{}, weekdays...), dayLabels...) {
+		if w := lipgloss.Width(label); w > width {
+			width = w
+		}
+	}
+	return width + 1
+}
+
+// padCell pads text with spaces to the given display width
+func padCell(text string, width int) string {
+	if pad := width - lipgloss.Width(text); pad > 0 {
+		return text + strings.Repeat(" ", pad)
+	}
+	return text
+}
+
 // renderCalendar renders the calendar view
 func (m Model) renderCalendar() string {
+	// The form's date picker always shows the whole month
+	if m.calendarLayout == WeekLayout && !m.pickingDate {
+		return m.renderCalendarWeek()
+	}
+
 	var sb strings.Builder
 
 	// Get the first day of the month
@@ -313,8 +879,8 @@ func (m Model) renderCalendar() string {
 	// Get the last day of the month
 	lastDay := firstDay.AddDate(0, 1, 0).AddDate(0, 0, -1)
 
-	// Get the weekday of the first day
-	firstWeekday := int(firstDay.Weekday())
+	// Get the column of the first day, counted from the configured start of the week
+	firstWeekday := (int(firstDay.Weekday()) - int(m.weekStart()) + 7) % 7
 
 	// Calculate how many days are in the month
 	daysInMonth := lastDay.Day()
@@ -329,24 +895,13 @@ func (m Model) renderCalendar() string {
 	sb.WriteString(monthYearHeader)
 	sb.WriteString("\n\n")
 
-	// Display the weekday headers
-	weekdays := []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
-	weekdayRow := ""
-	for _, day := range weekdays {
-		weekdayRow += fmt.Sprintf("%-4s", day)
-	}
-	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(weekdayRow))
-	sb.WriteString("\n")
-
 	// Build a map of days that have tasks
 	daysWithTasks := make(map[int]bool)
 
 	// Query the database for days in this month that have tasks
-	startDateStr := firstDay.Format("2006-01-02")
-	endDateStr := lastDay.Format("2006-01-02")
-
-	query := fmt.Sprintf("SELECT DISTINCT strftime('%%d', duedate) FROM todos WHERE date(duedate) BETWEEN date('%s') AND date('%s')", startDateStr, endDateStr)
-	rows, err := m.db.Query(query)
+	rangeClause, rangeArgs := database.BuildDateRangeClause(firstDay, lastDay)
+	query := "SELECT DISTINCT strftime('%d', duedate) FROM todos WHERE deleted_at IS NULL AND " + rangeClause
+	rows, err := m.db.Query(query, rangeArgs...)
 	if err != nil {
 		sb.WriteString(fmt.Sprintf("Error querying calendar data: %v", err))
 		return sb.String()
@@ -367,8 +922,41 @@ func (m Model) renderCalendar() string {
 		daysWithTasks[day] = true
 	}
 
+	// Size every cell from the widest header or day label so columns stay aligned
+	weekdays := weekdayNames(m.weekStart())
+	dayLabels := make([]string, daysInMonth+1)
+	for day := 1; day <= daysInMonth; day++ {
+		dayLabels[day] = strconv.Itoa(day)
+	}
+	cellWidth := calendarCellWidth(weekdays, dayLabels)
+
+	// Shrink the weekday names on narrow terminals
+	if m.width > 0 && 7*cellWidth > m.width {
+		for i, day := range weekdays {
+			weekdays[i] = day[:2]
+		}
+		cellWidth = calendarCellWidth(weekdays, dayLabels)
+	}
+	overflow := m.width > 0 && 7*cellWidth > m.width
+
+	// Spread the columns over the whole width; the form's date picker stays compact
+	if !m.pickingDate && m.width/7 > cellWidth {
+		cellWidth = m.width / 7
+	}
+
+	// Display the weekday headers
+	weekdayRow := ""
+	for _, day := range weekdays {
+		weekdayRow += padCell(day, cellWidth)
+	}
+	sb.WriteString(lipgloss.NewStyle().Bold(true).Render(weekdayRow))
+	sb.WriteString("\n")
+
 	// Now render the calendar grid
 	currentDay := 1
+	rowHeight := m.calendarRowHeight((firstWeekday+daysInMonth+6)/7, overflow)
+	cellStyle := lipgloss.NewStyle().Width(cellWidth).Height(rowHeight)
+	emptyCell := cellStyle.Render("")
 
 	// Create each row of the calendar
 	for week := 0; week < 6; week++ {
@@ -377,15 +965,15 @@ func (m Model) renderCalendar() string {
 		}
 
 		// Start a new row
-		row := ""
+		var cells []string
 
 		for weekday := 0; weekday < 7; weekday++ {
 			if week == 0 && weekday < firstWeekday {
 				// Empty cell before the first day of the month
-				row += "    "
+				cells = append(cells, emptyCell)
 			} else if currentDay <= daysInMonth {
 				// Determine the style for this day
-				dayStyle := lipgloss.NewStyle()
+				dayStyle := cellStyle
 
 				// Check if this is the selected day (highest priority)
 				isSelected := currentDay == m.calendarSelectedDay
@@ -411,23 +999,159 @@ func (m Model) renderCalendar() string {
 				}
 
 				// Render the day with appropriate styling
-				row += dayStyle.Render(fmt.Sprintf("%-4d", currentDay))
+				cells = append(cells, dayStyle.Render(dayLabels[currentDay]))
 
 				currentDay++
 			} else {
 				// Empty cell after the last day of the month
-				row += "    "
+				cells = append(cells, emptyCell)
 			}
 		}
 
-		sb.WriteString(row)
+		sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, cells...))
 		sb.WriteString("\n")
 	}
 
+	if overflow {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.ErrorColor)).Render(
+			"(terminal too narrow, calendar is cut off)"))
+		sb.WriteString("\n")
+	}
+
+	// The form's date picker shows its keys in the help bar instead
+	if m.pickingDate {
+		return sb.String()
+	}
+
 	// Add navigation instructions
 	sb.WriteString("\n")
 	sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.NormalTextColor)).Render(
-		"Navigate: ←→↑↓  |  Select day: enter  |  Return to today: esc  |  Exit: ctrl+c"))
+		"Navigate: ←→↑↓  |  Select day: enter  |  Week layout: " + m.keyMap.CalendarLayout.Help().Key + "  |  Return to today: esc  |  Exit: ctrl+c"))
+
+	return sb.String()
+}
+
+// calendarRowHeight returns how many lines each of the given number of week rows gets: one, or
+// with calendar_fill_height an equal share of the terminal height left by the rest of the screen
+func (m Model) calendarRowHeight(weeks int, overflow bool) int {
+	if !m.config.CalendarFillHeight || m.pickingDate || m.height <= 0 || weeks == 0 {
+		return 1
+	}
+
+	// Month header, blank line, weekday headers, blank line and navigation footer
+	used := 5 + lipgloss.Height(m.helpBar())
+	if overflow {
+		used++
+	}
+	if m.banner != "" {
+		used += lipgloss.Height(m.banner) + 1
+	}
+	if m.err != nil {
+		used += 2
+	}
+	return max(1, (m.height-used)/weeks)
+}
+
+// maxPaletteRows is how many actions the command palette lists at once
+const maxPaletteRows = 12
+
+// weekdayNames returns the short weekday names in calendar column order, starting at start
+func weekdayNames(start time.Weekday) []string {
+	names := make([]string, 7)
+	for i := range names {
+		names[i] = time.Weekday((int(start) + i) % 7).String()[:3]
+	}
+	return names
+}
+
+// truncate shortens text to at most width cells, ending in "…" when cut
+func truncate(text string, width int) string {
+	if lipgloss.Width(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// maxWeekLayoutTasks is how many tasks each day of the week layout lists
+const maxWeekLayoutTasks = 8
+
+// renderCalendarWeek renders the week of the selected day as seven columns listing each day's tasks
+func (m Model) renderCalendarWeek() string {
+	var sb strings.Builder
+
+	selected := time.Date(m.calendarMonth.Year(), m.calendarMonth.Month(), m.calendarSelectedDay, 0, 0, 0, 0, m.calendarMonth.Location())
+	start := m.startOfWeek(selected)
+	end := start.AddDate(0, 0, 6)
+
+	sb.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(m.styles.SelectedTextColor)).
+		Background(lipgloss.Color(m.styles.AccentColor)).
+		Padding(0, 1).
+		Render(fmt.Sprintf(" Week of %s ", start.Format("January 2, 2006"))))
+	sb.WriteString("\n\n")
+
+	whereClause, args := database.BuildDateRangeClause(start, end)
+	tasks, err := database.LoadTasksSorted(m.db, whereClause, "duedate ASC, id ASC", args...)
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("Error querying calendar data: %v", err))
+		return sb.String()
+	}
+	byDay := make(map[string][]database.TodoItem)
+	for _, task := range tasks {
+		day := task.DueDate.Format("2006-01-02")
+		byDay[day] = append(byDay[day], task)
+	}
+
+	// Share the width between the days, leaving a space between columns
+	cellWidth := 16
+	if m.width > 0 {
+		cellWidth = max(m.width/7-1, 8)
+	}
+
+	today := m.viewDate
+	columns := make([]string, 7)
+	for i := range columns {
+		day := start.AddDate(0, 0, i)
+		dateStr := day.Format("2006-01-02")
+
+		headerStyle := lipgloss.NewStyle().Bold(true).Width(cellWidth)
+		if day.Equal(selected) {
+			headerStyle = headerStyle.Background(lipgloss.Color(m.styles.AccentColor)).
+				Foreground(lipgloss.Color(m.styles.SelectedTextColor))
+		} else if dateStr == today.Format("2006-01-02") {
+			headerStyle = headerStyle.Background(lipgloss.Color(m.styles.SelectedBgColor)).
+				Foreground(lipgloss.Color(m.styles.SelectedTextColor))
+		}
+		lines := []string{headerStyle.Render(truncate(day.Format("Mon 2"), cellWidth))}
+
+		dayTasks := byDay[dateStr]
+		for j, task := range dayTasks {
+			if j == maxWeekLayoutTasks {
+				lines = append(lines, fmt.Sprintf("+%d more", len(dayTasks)-j))
+				break
+			}
+			title := task.Title
+			if title == "" {
+				title = task.Description
+			}
+			line := truncate(stateMarker(task.State)+" "+title, cellWidth)
+			if task.Status {
+				line = lipgloss.NewStyle().Faint(true).Render(line)
+			}
+			lines = append(lines, line)
+		}
+		columns[i] = lipgloss.NewStyle().Width(cellWidth).MarginRight(1).Render(strings.Join(lines, "\n"))
+	}
+	sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+	sb.WriteString("\n\n")
+
+	sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.styles.NormalTextColor)).Render(
+		"Navigate: ←→ day, ↑↓ week  |  Select day: enter  |  Month layout: " + m.keyMap.CalendarLayout.Help().Key + "  |  Return to today: esc  |  Exit: ctrl+c"))
 
 	return sb.String()
 }
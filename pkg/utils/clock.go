@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+// Now returns the current time; swap it out to control the clock
+var Now = time.Now
+
+// location is the time zone that decides which calendar day it is
+var location = time.Local
+
+// SetTimezone sets the time zone used for "today" from an IANA name such as "Europe/Berlin";
+// an empty name or "Local" uses the system zone
+func SetTimezone(name string) error {
+	if name == "" || name == "Local" {
+		location = time.Local
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	location = loc
+	return nil
+}
+
+// SetLocation sets the time zone used for "today"
+func SetLocation(loc *time.Location) {
+	location = loc
+}
+
+// Location returns the time zone used for "today"
+func Location() *time.Location {
+	return location
+}
+
+// Today returns the current time in the configured zone, shifted so that hours before cutoffHour
+// still count as the previous day (e.g. 02:00 with a cutoff of 3 belongs to yesterday)
+func Today(cutoffHour int) time.Time {
+	return Now().In(location).Add(-time.Duration(cutoffHour) * time.Hour)
+}
+
+// DateOnly returns midnight UTC of t's calendar day. Due dates are stored this way so that
+// SQLite's date() yields the same day whatever zone the date was entered in.
+func DateOnly(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// CountdownLabel describes how far off a due date is from now by calendar day: "3 days left",
+// "due today", "overdue by 2 days", or "no deadline" for a zero due date
+func CountdownLabel(due, now time.Time) string {
+	if due.IsZero() {
+		return "no deadline"
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(DateOnly(due).Sub(today).Hours() / 24)
+	switch {
+	case days == 0:
+		return "due today"
+	case days == 1:
+		return "1 day left"
+	case days > 1:
+		return fmt.Sprintf("%d days left", days)
+	case days == -1:
+		return "overdue by 1 day"
+	default:
+		return fmt.Sprintf("overdue by %d days", -days)
+	}
+}
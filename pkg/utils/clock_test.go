@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// setClock fixes Now and the time zone for the rest of the test
+func setClock(t *testing.T, now time.Time) {
+	t.Helper()
+
+	prevNow, prevLocation := Now, location
+	Now = func() time.Time { return now }
+	location = now.Location()
+	t.Cleanup(func() { Now, location = prevNow, prevLocation })
+}
+
+func TestTodayCutoff(t *testing.T) {
+	tests := []struct {
+		now        string
+		cutoffHour int
+		want       string
+	}{
+		{"2026-10-17 02:59", 0, "2026-10-17"},
+		{"2026-10-17 00:00", 3, "2026-10-16"},
+		{"2026-10-17 02:59", 3, "2026-10-16"},
+		{"2026-10-17 03:00", 3, "2026-10-17"},
+		{"2026-10-17 23:59", 3, "2026-10-17"},
+		{"2026-10-01 01:30", 3, "2026-09-30"},
+		{"2027-01-01 01:30", 3, "2026-12-31"},
+	}
+
+	for _, tt := range tests {
+		now, err := time.ParseInLocation("2006-01-02 15:04", tt.now, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		setClock(t, now)
+
+		if got := Today(tt.cutoffHour).Format("2006-01-02"); got != tt.want {
+			t.Errorf("Today(%d) at %s = %s, want %s", tt.cutoffHour, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestCountdownLabel(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	now := time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC)
+	tokyo := time.FixedZone("UTC+9", 9*3600)
+
+	tests := []struct {
+		name string
+		due  time.Time
+		now  time.Time
+		want string
+	}{
+		{"no due date", time.Time{}, now, "no deadline"},
+		{"today, late in the day", day("2026-10-17"), now, "due today"},
+		{"today, just after midnight", day("2026-10-17"), time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC), "due today"},
+		{"tomorrow", day("2026-10-18"), now, "1 day left"},
+		{"in two days", day("2026-10-19"), now, "2 days left"},
+		{"next year", day("2027-01-01"), now, "76 days left"},
+		{"yesterday", day("2026-10-16"), now, "overdue by 1 day"},
+		{"two days ago", day("2026-10-15"), now, "overdue by 2 days"},
+		{"last month", day("2026-09-30"), now, "overdue by 17 days"},
+		{"now in another zone", day("2026-10-18"), time.Date(2026, 10, 18, 1, 0, 0, 0, tokyo), "due today"},
+		{"due in another zone", time.Date(2026, 10, 18, 0, 30, 0, 0, tokyo), now, "1 day left"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountdownLabel(tt.due, tt.now); got != tt.want {
+				t.Errorf("CountdownLabel(%v, %v) = %q, want %q", tt.due, tt.now, got, tt.want)
+			}
+		})
+	}
+}
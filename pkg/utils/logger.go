@@ -3,40 +3,114 @@ package utils
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
+const (
+	logPrefix     = "awp_"
+	logSuffix     = ".log"
+	logDateFormat = "2006-01-02"
+)
+
 // Logger for debug messages
 var (
 	isVerbose = false
 	logFile   *os.File
+	pending   []string // Messages logged before the log file is configured
 )
 
 // Log prints debug messages to the log file if verbose mode is enabled
 func Log(text string, args ...interface{}) {
-	if isVerbose && logFile != nil {
-		fmt.Fprintf(logFile, text+"\n", args...)
+	if !isVerbose {
+		return
 	}
+	if logFile == nil {
+		pending = append(pending, fmt.Sprintf(text+"\n", args...))
+		return
+	}
+	fmt.Fprintf(logFile, text+"\n", args...)
 }
 
-// InitLogger initializes the logging system
+// InitLogger initializes the logging system. Messages are kept in memory until ConfigureLogger
+// opens the log file, so that loading the config, which says where the logs go, can log too.
 func InitLogger(verbose bool) {
 	isVerbose = verbose
+	pending = nil
+}
+
+// ConfigureLogger opens today's awp_<date>.log in dir (/tmp when empty) in verbose mode, writes
+// the messages logged so far and removes logs older than retentionDays
+func ConfigureLogger(dir string, retentionDays int) {
+	if !isVerbose {
+		return
+	}
+
+	if dir == "" {
+		dir = "/tmp"
+	}
+	dir, err := ExpandHome(dir)
+	if err != nil {
+		fmt.Printf("Error creating log file: %v\n", err)
+		return
+	}
+
+	logFile, err = rotateAndPrune(dir, time.Now(), retentionDays)
+	if err != nil {
+		fmt.Printf("Error creating log file: %v\n", err)
+		return
+	}
+
+	for _, line := range pending {
+		fmt.Fprint(logFile, line)
+	}
+	pending = nil
+	Log("Verbose logging enabled")
+}
+
+// rotateAndPrune opens the log file for today in dir for appending and removes the logs there
+// that are older than retentionDays (none for 0)
+func rotateAndPrune(dir string, today time.Time, retentionDays int) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
 
-	if verbose {
-		// Create log filename with current date
-		now := time.Now()
-		logFileName := fmt.Sprintf("/tmp/awp_%s.log", now.Format("2006-01-02"))
+	logFileName := filepath.Join(dir, logPrefix+today.Format(logDateFormat)+logSuffix)
+	file, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
 
-		var err error
-		logFile, err = os.Create(logFileName)
+	if retentionDays <= 0 {
+		return file, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return file, nil // Keep logging even if old logs cannot be listed
+	}
+
+	cutoff := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, -retentionDays)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, logPrefix) || !strings.HasSuffix(name, logSuffix) {
+			continue
+		}
+
+		dateStr := strings.TrimSuffix(strings.TrimPrefix(name, logPrefix), logSuffix)
+		logDate, err := time.ParseInLocation(logDateFormat, dateStr, time.Local)
 		if err != nil {
-			fmt.Printf("Error creating log file: %v\n", err)
-			return
+			continue
 		}
 
-		Log("Verbose logging enabled")
+		if logDate.Before(cutoff) {
+			os.Remove(filepath.Join(dir, name))
+		}
 	}
+
+	return file, nil
 }
 
 // CloseLogger closes the log file if it's open
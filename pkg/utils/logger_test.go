@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateAndPrune(t *testing.T) {
+	today := time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)
+	tests := []struct {
+		name          string
+		retentionDays int
+		want          []string
+	}{
+		{"keep all", 0, []string{"awp_2026-09-01.log", "awp_2026-10-09.log", "awp_2026-10-10.log", "awp_2026-10-16.log", "awp_2026-10-17.log", "awp_notes.log", "other.log"}},
+		{"a week", 7, []string{"awp_2026-10-10.log", "awp_2026-10-16.log", "awp_2026-10-17.log", "awp_notes.log", "other.log"}},
+		{"a day", 1, []string{"awp_2026-10-16.log", "awp_2026-10-17.log", "awp_notes.log", "other.log"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"awp_2026-09-01.log", "awp_2026-10-09.log", "awp_2026-10-10.log", "awp_2026-10-16.log", "awp_2026-10-17.log", "awp_notes.log", "other.log"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte("earlier\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			file, err := rotateAndPrune(dir, today, tt.retentionDays)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file.WriteString("later\n")
+			file.Close()
+
+			var got []string
+			entries, _ := os.ReadDir(dir)
+			for _, entry := range entries {
+				got = append(got, entry.Name())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("logs left = %q, want %q", got, tt.want)
+			}
+
+			// Today's log is appended to, not truncated
+			data, _ := os.ReadFile(filepath.Join(dir, "awp_2026-10-17.log"))
+			if string(data) != "earlier\nlater\n" {
+				t.Errorf("today's log = %q", data)
+			}
+		})
+	}
+}
+
+func TestLogBeforeConfigure(t *testing.T) {
+	dir := t.TempDir()
+	InitLogger(true)
+	t.Cleanup(func() {
+		CloseLogger()
+		isVerbose, logFile = false, nil
+	})
+
+	Log("before %d", 1)
+	ConfigureLogger(dir, 0)
+	Log("after %d", 2)
+
+	data, err := os.ReadFile(filepath.Join(dir, logPrefix+time.Now().Format(logDateFormat)+logSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "before 1\n") || !strings.Contains(string(data), "after 2\n") {
+		t.Errorf("log = %q, want both messages", data)
+	}
+	if strings.Index(string(data), "before 1") > strings.Index(string(data), "after 2") {
+		t.Errorf("log = %q, messages out of order", data)
+	}
+}
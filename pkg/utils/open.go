@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/atotto/clipboard"
+)
+
+// CopyToClipboard writes text to the system clipboard
+func CopyToClipboard(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// OpenURL opens a URL (or file path) with the operating system's default handler
+func OpenURL(url string) error {
+	var name string
+	var args []string
+
+	switch runtime.GOOS {
+	case "darwin":
+		name = "open"
+	case "windows":
+		name = "rundll32"
+		args = []string{"url.dll,FileProtocolHandler"}
+	default:
+		name = "xdg-open"
+	}
+
+	// Make sure the opener exists before trying to run it
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("no opener available: %w", err)
+	}
+
+	Log("Opening %s with %s", url, name)
+	return exec.Command(name, append(args, url)...).Start()
+}